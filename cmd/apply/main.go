@@ -4,42 +4,265 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/heaths/go-template"
+	"github.com/heaths/go-template/internal/functions"
 	"github.com/spf13/cobra"
 )
 
+type globalOptions struct {
+	params      map[string]string
+	paramsFiles []string
+	envPrefix   string
+	answersFile string
+	noGit       bool
+	defaults    bool
+	noInput     bool
+	keepPartial bool
+	verbose     bool
+}
+
+func (opts *globalOptions) apply(ctx context.Context, args []string, options ...template.ApplyOption) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	return template.ApplyContext(ctx, root, opts.parameters(), opts.options(options...)...)
+}
+
+func (opts *globalOptions) parameters() map[string]string {
+	if opts.params == nil {
+		opts.params = make(map[string]string)
+	}
+	return opts.params
+}
+
+// options returns options for all global flags followed by any additional options.
+func (opts *globalOptions) options(options ...template.ApplyOption) []template.ApplyOption {
+	options = append([]template.ApplyOption{
+		template.WithLogger(log.Default(), opts.verbose),
+		template.WithKeepPartial(opts.keepPartial),
+	}, options...)
+
+	if opts.defaults || opts.noInput {
+		options = append(options, template.WithNonInteractive(opts.defaults))
+	}
+
+	if opts.answersFile != "" {
+		options = append(options, template.WithAnswersFile(opts.answersFile))
+	}
+
+	if opts.envPrefix != "" {
+		options = append(options, template.WithEnvParams(opts.envPrefix))
+	}
+
+	if !opts.noGit {
+		options = append(options, template.WithGitDefaults())
+	}
+
+	for _, path := range opts.paramsFiles {
+		options = append(options, template.WithParamsFile(path))
+	}
+
+	return options
+}
+
 func main() {
-	var params map[string]string
-	verbose := false
+	opts := &globalOptions{}
+	dryRun := false
 	cmd := &cobra.Command{
 		Use:   "[flags] [root]",
 		Short: "Process template files in a root directory (default is $PWD)",
 		Args:  cobra.MaximumNArgs(1),
+
+		// Errors are printed with any template excerpts below.
+		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Only show usage for invalid flags and arguments.
+			cmd.Root().SilenceUsage = true
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !dryRun {
+				return opts.apply(cmd.Context(), args)
+			}
+
+			var plan template.Plan
+			err := opts.apply(cmd.Context(), args, template.WithDryRun(&plan))
+			printPlan(cmd.OutOrStdout(), &plan)
+
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print changes without writing any files")
+	cmd.PersistentFlags().StringToStringVarP(&opts.params, "param", "p", nil, "template parameters like name=value")
+	cmd.PersistentFlags().StringArrayVar(&opts.paramsFiles, "params-file", nil, "read parameters from a JSON, YAML, TOML, or .env file, or \"-\" for stdin")
+	cmd.PersistentFlags().StringVar(&opts.envPrefix, "env-prefix", "", "read parameters from environment variables like PREFIX_GITHUB_OWNER for github.owner")
+	cmd.PersistentFlags().StringVar(&opts.answersFile, "answers-file", template.DefaultAnswersFile, "file in the root directory in which answers are saved, or empty to not save answers")
+	cmd.PersistentFlags().BoolVar(&opts.noGit, "no-git-defaults", false, "do not read default parameters like git.name and github.repo from git config")
+	cmd.PersistentFlags().BoolVar(&opts.defaults, "defaults", false, "use default values instead of prompting for parameters")
+	cmd.PersistentFlags().BoolVar(&opts.noInput, "no-input", false, "never prompt for parameters")
+	cmd.PersistentFlags().BoolVar(&opts.keepPartial, "keep-partial", false, "write successfully processed templates even if others failed")
+	cmd.PersistentFlags().BoolVarP(&opts.verbose, "verbose", "v", false, "log verbose output")
+
+	cmd.AddCommand(newDiffCmd(opts))
+	cmd.AddCommand(newParamsCmd(opts))
+	cmd.AddCommand(newUpgradeCmd(opts))
+
+	// Cancel processing templates on Ctrl+C, including while prompting.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := cmd.ExecuteContext(ctx)
+	stop()
+
+	if err != nil {
+		printError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newDiffCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [flags] [root]",
+		Short: "Print a unified diff of changes without writing any files",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.apply(cmd.Context(), args, template.WithDiff(cmd.OutOrStdout()))
+		},
+	}
+}
+
+func newParamsCmd(opts *globalOptions) *cobra.Command {
+	asJSON := false
+	cmd := &cobra.Command{
+		Use:   "params [flags] [root]",
+		Short: "List parameters used by templates without processing them",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			root := "."
 			if len(args) > 0 {
 				root = args[0]
 			}
 
-			if params == nil {
-				params = make(map[string]string)
+			params, err := template.ListParams(root, template.WithLogger(log.Default(), opts.verbose))
+			if err != nil {
+				return err
 			}
 
-			return template.Apply(
-				root,
-				params,
-				template.WithLogger(log.Default(), verbose),
-			)
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(params)
+			}
+
+			printParams(cmd.OutOrStdout(), params)
+			return nil
 		},
 	}
 
-	cmd.Flags().StringToStringVarP(&params, "param", "p", nil, "template parameters like name=value")
-	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "log verbose output")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print parameters as JSON")
 
-	err := cmd.Execute()
-	if err != nil {
-		log.Fatalln(err)
+	return cmd
+}
+
+func newUpgradeCmd(opts *globalOptions) *cobra.Command {
+	dryRun := false
+	cmd := &cobra.Command{
+		Use:   "upgrade [flags] <old> <new> [project]",
+		Short: "Merge changes between two template revisions into a project (default is $PWD)",
+		Long: "Render the template revisions in directories <old> and <new> with the answers saved in the project,\n" +
+			"and merge the changes between them into the project. Conflicting changes are surrounded by conflict markers.",
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project := "."
+			if len(args) > 2 {
+				project = args[2]
+			}
+
+			var plan template.Plan
+			var options []template.ApplyOption
+			if dryRun {
+				options = append(options, template.WithDryRun(&plan))
+			}
+
+			result, err := template.UpgradeContext(cmd.Context(), args[0], args[1], project, opts.parameters(), opts.options(options...)...)
+			if dryRun {
+				printPlan(cmd.OutOrStdout(), &plan)
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, path := range result.Conflicts {
+				fmt.Fprintf(cmd.ErrOrStderr(), "CONFLICT %s\n", path)
+			}
+			if len(result.Conflicts) > 0 {
+				return fmt.Errorf("merged %s with conflicts", functions.Pluralize(len(result.Conflicts), "file"))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print changes without writing any files")
+
+	return cmd
+}
+
+func printParams(w io.Writer, params []*template.Param) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tPROMPT\tUSES")
+	for _, param := range params {
+		var def string
+		if param.Default != nil {
+			def = fmt.Sprint(param.Default)
+		}
+
+		uses := make([]string, len(param.Uses))
+		for i, use := range param.Uses {
+			if use.Line == 0 {
+				// Used in the path.
+				uses[i] = use.Path
+				continue
+			}
+			uses[i] = fmt.Sprintf("%s:%d:%d", use.Path, use.Line, use.Column)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", param.Name, param.Type, def, param.Prompt, strings.Join(uses, ", "))
+	}
+	tw.Flush()
+}
+
+func printPlan(w io.Writer, plan *template.Plan) {
+	for _, change := range plan.Changes {
+		fmt.Fprintf(w, "%-8s %s\n", change.Action, change.Path)
+	}
+}
+
+func printError(w io.Writer, err error) {
+	if errs, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range errs.Unwrap() {
+			printError(w, err)
+		}
+		return
+	}
+
+	fmt.Fprintln(w, err)
+
+	var templateErr *template.TemplateError
+	if errors.As(err, &templateErr) {
+		if excerpt := templateErr.Excerpt(); excerpt != "" {
+			fmt.Fprintln(w, excerpt)
+		}
 	}
 }
@@ -3,20 +3,21 @@ module github.com/heaths/go-template
 go 1.19
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/heaths/go-console v0.8.0
 	github.com/mattn/go-isatty v0.0.16
 	github.com/spf13/afero v1.9.3
 	github.com/spf13/cobra v1.6.1
 	github.com/stretchr/testify v1.8.1
 	golang.org/x/exp v0.0.0-20221114191408-850992195362
+	golang.org/x/term v0.2.0
 	golang.org/x/text v0.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/term v0.2.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 require (
@@ -0,0 +1,239 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DevNull is the name used for a missing file in unified diff headers.
+const DevNull = "/dev/null"
+
+const contextLines = 3
+
+type op int
+
+const (
+	opEqual op = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	op op
+	a  int // Index into a for opEqual and opDelete.
+	b  int // Index into b for opEqual and opInsert.
+}
+
+// Lines splits s into lines, each retaining its trailing line feed if present.
+func Lines(s []byte) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(s), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// Unified writes a unified diff between from and to to w using
+// fromName and toName in the headers. Nothing is written if from and to are equal.
+func Unified(w io.Writer, fromName, toName string, from, to []byte) error {
+	a, b := Lines(from), Lines(to)
+	edits := compute(a, b)
+
+	hunks := group(edits)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range hunks {
+		var aStart, aCount, bStart, bCount int
+		aStart, bStart = -1, -1
+		for _, e := range h {
+			if e.op != opInsert {
+				if aStart < 0 {
+					aStart = e.a
+				}
+				aCount++
+			}
+			if e.op != opDelete {
+				if bStart < 0 {
+					bStart = e.b
+				}
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", span(aStart, aCount, h[0].a), span(bStart, bCount, h[0].b))
+		for _, e := range h {
+			switch e.op {
+			case opEqual:
+				writeLine(buf, ' ', a[e.a])
+			case opDelete:
+				writeLine(buf, '-', a[e.a])
+			case opInsert:
+				writeLine(buf, '+', b[e.b])
+			}
+		}
+	}
+
+	return buf.Flush()
+}
+
+func span(start, count, at int) string {
+	if count == 0 {
+		// An empty range refers to the line before the hunk.
+		return fmt.Sprintf("%d,0", at)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeLine(w *bufio.Writer, prefix byte, line string) {
+	w.WriteByte(prefix)
+	w.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		w.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// compute returns the shortest edit script to transform a into b using the Myers algorithm.
+func compute(a, b []string) []edit {
+	n, m := len(a), len(b)
+	switch {
+	case n+m == 0:
+		return nil
+	case n == 0 || m == 0:
+		// Avoid tracing every step when all lines are inserted or deleted.
+		edits := make([]edit, 0, n+m)
+		for x := 0; x < n; x++ {
+			edits = append(edits, edit{op: opDelete, a: x})
+		}
+		for y := 0; y < m; y++ {
+			edits = append(edits, edit{op: opInsert, b: y})
+		}
+		return edits
+	}
+
+	max := n + m
+	offset := max + 1
+
+	// Snapshot only diagonals -d through d before each step d, which is all backtracking reads.
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Backtrack through each snapshot to recover the edits in reverse.
+	edits := make([]edit, 0, max)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		var prevX int
+		if d > 0 {
+			prevX = v[d+prevK]
+		}
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{op: opEqual, a: x, b: y})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, edit{op: opInsert, a: x, b: y})
+			} else {
+				x--
+				edits = append(edits, edit{op: opDelete, a: x, b: y})
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
+
+// group splits edits into hunks of changes surrounded by up to contextLines of unchanged lines.
+func group(edits []edit) [][]edit {
+	var hunks [][]edit
+
+	start, end := -1, -1
+	for i, e := range edits {
+		if e.op == opEqual {
+			continue
+		}
+
+		lo := i - contextLines
+		if lo < 0 {
+			lo = 0
+		}
+
+		if start >= 0 && lo > end {
+			hunks = append(hunks, edits[start:end])
+			start = -1
+		}
+		if start < 0 {
+			start = lo
+		}
+
+		end = i + 1 + contextLines
+		if end > len(edits) {
+			end = len(edits)
+		}
+	}
+
+	if start >= 0 {
+		hunks = append(hunks, edits[start:end])
+	}
+
+	return hunks
+}
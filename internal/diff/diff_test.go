@@ -0,0 +1,149 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Lines(nil))
+	assert.Equal(t, []string{"a\n", "b"}, Lines([]byte("a\nb")))
+	assert.Equal(t, []string{"a\n", "b\n"}, Lines([]byte("a\nb\n")))
+}
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "equal",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+		},
+		{
+			name: "create",
+			to:   "a\nb\n",
+			want: heredoc.Doc(`
+				--- from
+				+++ to
+				@@ -0,0 +1,2 @@
+				+a
+				+b
+			`),
+		},
+		{
+			name: "delete",
+			from: "a\nb\n",
+			want: heredoc.Doc(`
+				--- from
+				+++ to
+				@@ -1,2 +0,0 @@
+				-a
+				-b
+			`),
+		},
+		{
+			name: "change",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			to:   "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: heredoc.Doc(`
+				--- from
+				+++ to
+				@@ -2,7 +2,7 @@
+				 2
+				 3
+				 4
+				-5
+				+five
+				 6
+				 7
+				 8
+			`),
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: heredoc.Doc(`
+				--- from
+				+++ to
+				@@ -1,4 +1,4 @@
+				-1
+				+one
+				 2
+				 3
+				 4
+				@@ -7,4 +7,4 @@
+				 7
+				 8
+				 9
+				-10
+				+ten
+			`),
+		},
+		{
+			name: "no newline",
+			from: "a\nb",
+			to:   "a\nc",
+			want: heredoc.Doc(`
+				--- from
+				+++ to
+				@@ -1,2 +1,2 @@
+				 a
+				-b
+				\ No newline at end of file
+				+c
+				\ No newline at end of file
+			`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Unified(&buf, "from", "to", []byte(tt.from), []byte(tt.to))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestCompute_large(t *testing.T) {
+	t.Parallel()
+
+	a := make([]string, 100000)
+	for i := range a {
+		a[i] = fmt.Sprintf("%d\n", i)
+	}
+
+	// All lines are deleted without tracing every step.
+	edits := compute(a, nil)
+	require.Len(t, edits, len(a))
+	assert.Equal(t, edit{op: opDelete, a: len(a) - 1}, edits[len(edits)-1])
+
+	// Few changes in many lines trace only a few diagonals.
+	b := append([]string(nil), a...)
+	b[50000] = "changed\n"
+	edits = compute(a, b)
+	require.Len(t, edits, len(a)+1)
+	assert.Equal(t, edit{op: opDelete, a: 50000, b: 50000}, edits[50000])
+	assert.Equal(t, edit{op: opInsert, a: 50001, b: 50000}, edits[50001])
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package diff
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// chunk replaces lines base[lo:hi] with lines.
+type chunk struct {
+	lo, hi int
+	lines  []string
+}
+
+// Merge merges the changes from base to theirs into ours, and returns the merged content
+// and whether any changes conflicted. Conflicting changes are surrounded by markers
+// using oursName and theirsName like git.
+func Merge(base, ours, theirs []byte, oursName, theirsName string) ([]byte, bool) {
+	a, o, t := Lines(base), Lines(ours), Lines(theirs)
+	oc, tc := chunks(a, o), chunks(a, t)
+
+	var out []string
+	var conflicted bool
+
+	pos := 0
+	for len(oc) > 0 || len(tc) > 0 {
+		// Start a group with the next chunk from either side.
+		lo, hi := -1, -1
+		if len(oc) > 0 {
+			lo, hi = oc[0].lo, oc[0].hi
+		}
+		if len(tc) > 0 && (lo < 0 || tc[0].lo < lo) {
+			lo, hi = tc[0].lo, tc[0].hi
+		}
+
+		// Extend the group with chunks from both sides that overlap or touch it.
+		var og, tg []chunk
+		for {
+			if len(oc) > 0 && oc[0].lo <= hi && oc[0].hi >= lo {
+				og, oc = append(og, oc[0]), oc[1:]
+				hi = max(hi, og[len(og)-1].hi)
+				continue
+			}
+			if len(tc) > 0 && tc[0].lo <= hi && tc[0].hi >= lo {
+				tg, tc = append(tg, tc[0]), tc[1:]
+				hi = max(hi, tg[len(tg)-1].hi)
+				continue
+			}
+			break
+		}
+
+		out = append(out, a[pos:lo]...)
+		pos = hi
+
+		oursLines, theirsLines := apply(a, lo, hi, og), apply(a, lo, hi, tg)
+		switch {
+		case len(og) == 0:
+			out = append(out, theirsLines...)
+		case len(tg) == 0 || slices.Equal(oursLines, theirsLines):
+			out = append(out, oursLines...)
+		default:
+			conflicted = true
+			out = append(out, "<<<<<<< "+oursName+"\n")
+			out = appendLines(out, oursLines)
+			out = append(out, "=======\n")
+			out = appendLines(out, theirsLines)
+			out = append(out, ">>>>>>> "+theirsName+"\n")
+		}
+	}
+	out = append(out, a[pos:]...)
+
+	return []byte(strings.Join(out, "")), conflicted
+}
+
+// chunks returns the changes needed to transform a into b.
+func chunks(a, b []string) []chunk {
+	var cs []chunk
+	var c *chunk
+
+	x := 0
+	for _, e := range compute(a, b) {
+		if e.op == opEqual {
+			if c != nil {
+				cs = append(cs, *c)
+				c = nil
+			}
+			x = e.a + 1
+			continue
+		}
+
+		if c == nil {
+			c = &chunk{lo: x, hi: x}
+		}
+
+		if e.op == opDelete {
+			x = e.a + 1
+			c.hi = x
+		} else {
+			c.lines = append(c.lines, b[e.b])
+		}
+	}
+
+	if c != nil {
+		cs = append(cs, *c)
+	}
+
+	return cs
+}
+
+// apply returns base[lo:hi] with chunks applied.
+func apply(base []string, lo, hi int, cs []chunk) []string {
+	var out []string
+	pos := lo
+	for _, c := range cs {
+		out = append(out, base[pos:c.lo]...)
+		out = append(out, c.lines...)
+		pos = c.hi
+	}
+	return append(out, base[pos:hi]...)
+}
+
+// appendLines appends lines to out, making sure the last line ends with a line feed before a marker.
+func appendLines(out, lines []string) []string {
+	out = append(out, lines...)
+	if n := len(out); n > 0 && !strings.HasSuffix(out[n-1], "\n") {
+		out[n-1] += "\n"
+	}
+	return out
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
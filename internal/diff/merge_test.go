@@ -0,0 +1,121 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		base     string
+		ours     string
+		theirs   string
+		want     string
+		conflict bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "empty base",
+			ours:   "a\n",
+			theirs: "a\n",
+			want:   "a\n",
+		},
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			name:   "theirs",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\nd\n",
+			want:   "a\nB\nc\nd\n",
+		},
+		{
+			name:   "ours",
+			base:   "a\nb\nc\n",
+			ours:   "A\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "A\nb\nc\n",
+		},
+		{
+			name:   "both",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:   "same change",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			name:   "conflict",
+			base:   "a\nb\nc\n",
+			ours:   "a\nours\nc\n",
+			theirs: "a\ntheirs\nc\n",
+			want: heredoc.Doc(`
+				a
+				<<<<<<< project
+				ours
+				=======
+				theirs
+				>>>>>>> template
+				c
+			`),
+			conflict: true,
+		},
+		{
+			name:   "conflict without line feed",
+			base:   "a\nb",
+			ours:   "a\nours",
+			theirs: "a\ntheirs",
+			want: heredoc.Doc(`
+				a
+				<<<<<<< project
+				ours
+				=======
+				theirs
+				>>>>>>> template
+			`),
+			conflict: true,
+		},
+		{
+			name:   "both added",
+			ours:   "a\n",
+			theirs: "b\n",
+			want: heredoc.Doc(`
+				<<<<<<< project
+				a
+				=======
+				b
+				>>>>>>> template
+			`),
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := Merge([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), "project", "template")
+			assert.Equal(t, tt.want, string(got))
+			assert.Equal(t, tt.conflict, conflict)
+		})
+	}
+}
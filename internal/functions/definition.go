@@ -0,0 +1,221 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Definition declares a parameter e.g., in a manifest.
+type Definition struct {
+	Name    string   `json:"name" yaml:"name"`
+	Type    string   `json:"type,omitempty" yaml:"type,omitempty"`       // "string", "int", "bool", "choice", "list", or "secret"; otherwise, inferred from Default or Choices.
+	Default any      `json:"default,omitempty" yaml:"default,omitempty"` // The default value, if any.
+	Prompt  string   `json:"prompt,omitempty" yaml:"prompt,omitempty"`   // The prompt; otherwise, Name is used.
+	Help    string   `json:"help,omitempty" yaml:"help,omitempty"`       // Optional help displayed before the prompt.
+	Pattern string   `json:"pattern,omitempty" yaml:"pattern,omitempty"` // Optional regular expression a string value must match.
+	Choices []string `json:"choices,omitempty" yaml:"choices,omitempty"` // Values of which one must be chosen for a "choice", or any for a "list".
+	When    string   `json:"when,omitempty" yaml:"when,omitempty"`       // Optional template pipeline e.g., `param "docker"` that must be true to prompt; otherwise, the default is used.
+
+	MinLength *int `json:"minLength,omitempty" yaml:"minLength,omitempty"` // Optional minimum number of characters in a string.
+	MaxLength *int `json:"maxLength,omitempty" yaml:"maxLength,omitempty"` // Optional maximum number of characters in a string.
+	Min       *int `json:"min,omitempty" yaml:"min,omitempty"`             // Optional minimum value of an int.
+	Max       *int `json:"max,omitempty" yaml:"max,omitempty"`             // Optional maximum value of an int.
+
+	defaulted bool // Whether a default was passed or declared.
+}
+
+// Validate normalizes the Type and Default, and returns an error if either is invalid.
+func (d *Definition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("parameter name required")
+	}
+
+	d.defaulted = d.defaulted || d.Default != nil
+
+	// JSON numbers are always decoded as float64.
+	if f, ok := d.Default.(float64); ok && f == math.Trunc(f) {
+		d.Default = int(f)
+	}
+
+	// JSON and YAML arrays are decoded as []any.
+	if values, ok := d.Default.([]any); ok {
+		list := make(List, len(values))
+		for i, value := range values {
+			if list[i], ok = value.(string); !ok {
+				return fmt.Errorf("parameter %q default %v is not a list of strings", d.Name, d.Default)
+			}
+		}
+		d.Default = list
+	} else if values, ok := d.Default.([]string); ok {
+		d.Default = List(values)
+	}
+
+	if len(d.Choices) > 0 && d.Type == "" {
+		if _, ok := d.Default.(List); ok {
+			d.Type = "list"
+		} else {
+			d.Type = "choice"
+		}
+	}
+
+	if d.Type == "choice" && d.Default == nil && len(d.Choices) > 0 {
+		d.Default = d.Choices[0]
+		d.defaulted = true
+	}
+
+	if d.Type == "" {
+		if d.Default == nil {
+			d.Type = "string"
+		} else {
+			typ, err := DefaultType(d.Default)
+			if err != nil {
+				return fmt.Errorf("parameter %q default: %w", d.Name, err)
+			}
+			d.Type = typ
+		}
+	}
+
+	switch d.Type {
+	case "string":
+		if d.Default == nil {
+			d.Default = ""
+		}
+	case "int":
+		if d.Default == nil {
+			d.Default = 0
+		}
+	case "bool":
+		if d.Default == nil {
+			d.Default = false
+		}
+	case "choice":
+		if len(d.Choices) == 0 {
+			return fmt.Errorf("parameter %q requires choices", d.Name)
+		}
+	case "list":
+		if d.Default == nil {
+			d.Default = List{}
+		}
+	case "secret":
+		if d.Default == nil {
+			d.Default = Secret("")
+		} else if s, ok := d.Default.(string); ok {
+			d.Default = Secret(s)
+		}
+	default:
+		return fmt.Errorf("parameter %q has unsupported type %q", d.Name, d.Type)
+	}
+
+	if d.Type == "choice" {
+		if s, ok := d.Default.(string); !ok || !slices.Contains(d.Choices, s) {
+			return fmt.Errorf("parameter %q default %v is not one of %s", d.Name, d.Default, strings.Join(d.Choices, ", "))
+		}
+	} else if d.Type == "list" {
+		values, ok := d.Default.(List)
+		if !ok {
+			return fmt.Errorf("parameter %q default %v is not a list", d.Name, d.Default)
+		}
+		for _, value := range values {
+			if len(d.Choices) > 0 && !slices.Contains(d.Choices, value) {
+				return fmt.Errorf("parameter %q default %v is not any of %s", d.Name, d.Default, strings.Join(d.Choices, ", "))
+			}
+		}
+	} else if len(d.Choices) > 0 {
+		return fmt.Errorf("parameter %q with choices must be a choice or list", d.Name)
+	} else if typ, err := DefaultType(d.Default); err != nil || typ != d.Type {
+		return fmt.Errorf("parameter %q default %v is not a %s", d.Name, d.Default, d.Type)
+	}
+
+	if d.Pattern != "" {
+		if _, err := regexp.Compile(d.Pattern); err != nil {
+			return fmt.Errorf("parameter %q pattern: %w", d.Name, err)
+		}
+	}
+
+	if d.MinLength != nil || d.MaxLength != nil {
+		if d.Type != "string" && d.Type != "secret" {
+			return fmt.Errorf("parameter %q with a length must be a string or secret", d.Name)
+		}
+		if d.MinLength != nil && d.MaxLength != nil && *d.MinLength > *d.MaxLength {
+			return fmt.Errorf("parameter %q minLength %d is greater than maxLength %d", d.Name, *d.MinLength, *d.MaxLength)
+		}
+	}
+
+	if d.Min != nil || d.Max != nil {
+		if d.Type != "int" {
+			return fmt.Errorf("parameter %q with a min or max must be an int", d.Name)
+		}
+		if d.Min != nil && d.Max != nil && *d.Min > *d.Max {
+			return fmt.Errorf("parameter %q min %d is greater than max %d", d.Name, *d.Min, *d.Max)
+		}
+	}
+
+	return nil
+}
+
+// hasDefault returns whether a default other than an empty string was passed or declared.
+func (d *Definition) hasDefault() bool {
+	return d.defaulted && d.Default != "" && d.Default != Secret("")
+}
+
+// value returns the paramValue for the definition.
+func (d *Definition) value() (paramValue, error) {
+	switch d.Type {
+	case "choice":
+		return &choiceValue{d.Choices, d.Default.(string)}, nil
+	case "list":
+		return &listValue{d.Choices, d.Default.(List)}, nil
+	}
+
+	param, err := fromDefaultValue(d.Default)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Pattern != "" {
+		re, err := regexp.Compile(d.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		param = &patternValue{param, re}
+	}
+
+	if d.MinLength != nil || d.MaxLength != nil {
+		param = &lengthValue{param, d.MinLength, d.MaxLength}
+	}
+
+	if d.Min != nil || d.Max != nil {
+		param = &rangeValue{param, d.Min, d.Max}
+	}
+
+	return param, nil
+}
+
+// fromArgs returns a Definition from arguments passed to param.
+func fromArgs(name string, args []any) (*Definition, error) {
+	def := &Definition{
+		Name:    name,
+		Default: "",
+	}
+
+	if len(args) > 0 {
+		def.Default = args[0]
+		def.defaulted = true
+	}
+
+	if len(args) > 1 {
+		var ok bool
+		if def.Prompt, ok = args[1].(string); !ok {
+			return nil, fmt.Errorf("unsupported prompt %v", args[1])
+		}
+	}
+
+	return def, nil
+}
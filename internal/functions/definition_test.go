@@ -0,0 +1,184 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefinition_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		def         Definition
+		wantType    string
+		wantDefault any
+		wantErr     bool
+	}{
+		{
+			name:        "string",
+			def:         Definition{Name: "name"},
+			wantType:    "string",
+			wantDefault: "",
+		},
+		{
+			name:        "inferred int",
+			def:         Definition{Name: "year", Default: 2022},
+			wantType:    "int",
+			wantDefault: 2022,
+		},
+		{
+			name:        "JSON number",
+			def:         Definition{Name: "year", Default: float64(2022)},
+			wantType:    "int",
+			wantDefault: 2022,
+		},
+		{
+			name:        "bool",
+			def:         Definition{Name: "release", Type: "bool"},
+			wantType:    "bool",
+			wantDefault: false,
+		},
+		{
+			name:        "choice",
+			def:         Definition{Name: "license", Choices: []string{"mit", "apache-2.0"}},
+			wantType:    "choice",
+			wantDefault: "mit",
+		},
+		{
+			name:        "choice default",
+			def:         Definition{Name: "license", Type: "choice", Choices: []string{"mit", "apache-2.0"}, Default: "apache-2.0"},
+			wantType:    "choice",
+			wantDefault: "apache-2.0",
+		},
+		{
+			name:    "invalid choice default",
+			def:     Definition{Name: "license", Choices: []string{"mit", "apache-2.0"}, Default: "bsd"},
+			wantErr: true,
+		},
+		{
+			name:    "choice without choices",
+			def:     Definition{Name: "license", Type: "choice"},
+			wantErr: true,
+		},
+		{
+			name:    "choices without choice",
+			def:     Definition{Name: "license", Type: "string", Choices: []string{"mit"}},
+			wantErr: true,
+		},
+		{
+			name:        "list",
+			def:         Definition{Name: "platforms", Type: "list", Choices: []string{"linux", "windows"}},
+			wantType:    "list",
+			wantDefault: List{},
+		},
+		{
+			name:        "list default",
+			def:         Definition{Name: "platforms", Choices: []string{"linux", "windows"}, Default: []any{"windows"}},
+			wantType:    "list",
+			wantDefault: List{"windows"},
+		},
+		{
+			name:        "list without choices",
+			def:         Definition{Name: "platforms", Default: []any{"linux"}},
+			wantType:    "list",
+			wantDefault: List{"linux"},
+		},
+		{
+			name:    "invalid list default",
+			def:     Definition{Name: "platforms", Choices: []string{"linux", "windows"}, Default: []any{"bsd"}},
+			wantErr: true,
+		},
+		{
+			name:    "list default not strings",
+			def:     Definition{Name: "platforms", Default: []any{1}},
+			wantErr: true,
+		},
+		{
+			name:        "secret",
+			def:         Definition{Name: "token", Type: "secret"},
+			wantType:    "secret",
+			wantDefault: Secret(""),
+		},
+		{
+			name:        "secret default",
+			def:         Definition{Name: "token", Type: "secret", Default: "s3cr3t"},
+			wantType:    "secret",
+			wantDefault: Secret("s3cr3t"),
+		},
+		{
+			name:        "length",
+			def:         Definition{Name: "name", MinLength: intPtr(1), MaxLength: intPtr(64)},
+			wantType:    "string",
+			wantDefault: "",
+		},
+		{
+			name:    "length not a string",
+			def:     Definition{Name: "year", Default: 2022, MinLength: intPtr(4)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid length",
+			def:     Definition{Name: "name", MinLength: intPtr(2), MaxLength: intPtr(1)},
+			wantErr: true,
+		},
+		{
+			name:        "range",
+			def:         Definition{Name: "year", Default: 2022, Min: intPtr(1970)},
+			wantType:    "int",
+			wantDefault: 2022,
+		},
+		{
+			name:    "range not an int",
+			def:     Definition{Name: "name", Max: intPtr(1)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid range",
+			def:     Definition{Name: "year", Type: "int", Min: intPtr(2), Max: intPtr(1)},
+			wantErr: true,
+		},
+		{
+			name:    "no name",
+			def:     Definition{},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			def:     Definition{Name: "name", Type: "date"},
+			wantErr: true,
+		},
+		{
+			name:    "mismatched default",
+			def:     Definition{Name: "name", Type: "int", Default: "one"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			def:     Definition{Name: "name", Pattern: "["},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantType, tt.def.Type)
+			assert.Equal(t, tt.wantDefault, tt.def.Default)
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -4,9 +4,11 @@
 package functions
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"path"
 	"strconv"
 	"strings"
 
@@ -14,81 +16,363 @@ import (
 	"golang.org/x/text/language"
 )
 
-func ParamFunc(r io.Reader, w io.Writer, isTTY bool, params map[string]string) func(string, ...any) (string, error) {
-	return func(name string, args ...any) (value string, err error) {
-		var ok bool
-		if value, ok = params[name]; ok {
-			// Validate the provided value if a default value was defined.
-			if len(args) == 0 {
-				return
-			}
+// Resolver resolves parameter values from supplied values, environment variables, or by prompting.
+type Resolver struct {
+	Values      map[string]string             // Supplied values. Prompted values are added.
+	Definitions map[string]*Definition        // Optional definitions that take precedence over arguments passed to param.
+	Answers     map[string]string             // Optional previous answers used instead of prompting if still valid.
+	Resolved    map[string]string             // If not nil, every value returned by param is added except secrets.
+	Secrets     map[string]string             // If not nil, every secret returned by param is added.
+	Validators  map[string]func(string) error // Optional validators for parameter values by name.
+	Evaluate    func(string) (bool, error)    // Evaluates a Definition.When pipeline; if nil, conditions in definitions are ignored.
+	Render      func(string) (string, error)  // Renders a string default that references other parameters; if nil, defaults are used as is.
+	Defaults    map[string]string             // Optional defaults e.g., from git config for string parameters without a default.
 
-			var param paramValue
-			param, err = fromDefaultValue(args[0])
-			if err != nil {
-				return
-			}
+	EnvPrefix string                      // Optional prefix of environment variables from which values are read e.g., "TEMPLATE".
+	LookupEnv func(string) (string, bool) // Looks up environment variables; the default is os.LookupEnv.
 
-			if value, ok = param.Format(value); !ok {
-				return "", fmt.Errorf("invalid parameter %q value: %s; expected %s", name, value, param.Description())
-			}
+	Stdin    io.Reader // The reader from which user input is read.
+	Stderr   io.Writer // The writer on which users are prompted.
+	IsTTY    bool      // Whether Stderr is a terminal.
+	Prompter Prompter  // Optional Prompter used instead of prompting on Stderr, even if Stderr is not a terminal.
 
-			return
-		} else if !ok {
-			if !isTTY {
-				return "", fmt.Errorf("cannot prompt for parameter %q", name)
-			}
+	NonInteractive bool // Whether to never prompt even if Stderr is a terminal.
+	AcceptDefaults bool // Whether to use the default instead of prompting for parameters with a default.
 
-			var param paramValue
-			if len(args) > 0 {
-				param, err = fromDefaultValue(args[0])
-				if err != nil {
-					return
-				}
-			} else {
-				param = &stringValue{""}
-			}
+	evaluating map[string]bool // Parameters with conditions or defaults being evaluated.
+	formatted  map[string]bool // Parameters added to Values already formatted.
+	secret     map[string]bool // Parameters resolved as secrets.
+	list       map[string]bool // Parameters resolved as lists.
+}
 
-			prompt := name
-			if len(args) > 1 {
-				var ok bool
-				if prompt, ok = args[1].(string); !ok {
-					return "", fmt.Errorf("unsupported prompt %v", args[1])
-				}
-				prompt = strings.TrimRightFunc(prompt, func(r rune) bool {
-					return r == '?'
-				})
-				prompt = fmt.Sprintf("%s (%s)", prompt, name)
-			}
+// lookupEnv returns the value of the environment variable for the parameter name, if EnvPrefix is set.
+func (r *Resolver) lookupEnv(name string) (string, bool) {
+	if r.EnvPrefix == "" {
+		return "", false
+	}
 
-			reader := bufio.NewReader(r)
-			for {
-				// Assume color support since we're on a TTY.
-				fmt.Fprintf(w, "\033[32m%s? \033[90m[%s]\033[0m: ", prompt, param.Display())
+	lookup := r.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return lookup(EnvName(r.EnvPrefix, name))
+}
 
-				value, err = reader.ReadString('\n')
-				if err != nil {
-					return
-				}
+// EnvName returns the name of the environment variable for a parameter
+// e.g., "TEMPLATE_GITHUB_OWNER" for prefix "TEMPLATE" and name "github.owner".
+func EnvName(prefix, name string) string {
+	name = strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "_") + "_" + name
+}
+
+// ParamFunc returns the param template function. It returns a []string for list parameters;
+// otherwise, a string.
+func ParamFunc(ctx context.Context, resolver *Resolver) func(string, ...any) (any, error) {
+	prompter := resolver.Prompter
+	if prompter == nil {
+		prompter = newTTYPrompter(resolver.Stdin, resolver.Stderr)
+	}
+
+	return func(name string, args ...any) (any, error) {
+		value, param, err := resolver.resolve(ctx, prompter, name, args)
+		if err != nil {
+			return "", err
+		}
 
-				value = strings.TrimSpace(value)
-				if value == "" {
-					value = param.String()
-					break
+		// Skipped parameters are not resolved so users are prompted if their condition holds later.
+		if _, ok := resolver.Values[name]; ok {
+			if isSecret(param) || resolver.secret[name] {
+				// Parameters are secret if any reference to them is secret, including references without a default.
+				if resolver.secret == nil {
+					resolver.secret = make(map[string]bool)
 				}
+				resolver.secret[name] = true
+				delete(resolver.Resolved, name)
 
-				if value, ok = param.Format(value); ok {
-					break
+				if resolver.Secrets != nil {
+					resolver.Secrets[name] = value
 				}
+			} else if resolver.Resolved != nil {
+				// Save false as "false" since "" would be formatted as the default.
+				resolved := value
+				if param.Type() == "bool" && value == "" {
+					resolved = "false"
+				}
+				resolver.Resolved[name] = resolved
+			}
+		}
 
-				fmt.Fprintf(w, "\033[31mExpected %s. Please try again.\033[0m\n", param.Description())
+		// Lists may be wrapped e.g., by a validator.
+		if param.Type() == "list" {
+			if resolver.list == nil {
+				resolver.list = make(map[string]bool)
 			}
+			resolver.list[name] = true
+			return parseList(value)
+		}
+		return value, nil
+	}
+}
+
+// resolve returns the value of a parameter formatted as a string, and its paramValue.
+func (r *Resolver) resolve(ctx context.Context, prompter Prompter, name string, args []any) (value string, param paramValue, err error) {
+	// Conditions can be passed in any position after the name.
+	var conditions []Condition
+	for i := 0; i < len(args); i++ {
+		if condition, ok := args[i].(Condition); ok {
+			conditions = append(conditions, condition)
+			args = append(args[:i:i], args[i+1:]...)
+			i--
+		}
+	}
+
+	def, ok := r.Definitions[name]
+	if !ok {
+		if def, err = fromArgs(name, args); err != nil {
+			return
+		}
 
-			params[name] = value
+		// Parameters resolved as lists are lists when referenced again without a default.
+		if r.list[name] && !def.defaulted {
+			def = &Definition{Name: name, Type: "list", Default: List{}}
 		}
+	}
+
+	if value, ok := r.Defaults[name]; ok && def.Default == "" && (def.Type == "" || def.Type == "string") {
+		// Definitions may be shared, so default a copy.
+		d := *def
+		d.Default = value
+		d.defaulted = true
+		def = &d
+	}
 
+	param, err = r.value(def)
+	if err != nil {
 		return
 	}
+
+	// Never show secrets in errors.
+	show := func(s string) string {
+		if isSecret(param) {
+			return Mask
+		}
+		return s
+	}
+
+	if value, ok = r.Values[name]; ok {
+		// Some formatted values like "" for false are not formatted the same again.
+		if r.formatted[name] {
+			return
+		}
+
+		// Validate the provided value.
+		if value, err = param.Format(value); err != nil {
+			return "", nil, fmt.Errorf("invalid parameter %q value: %s; %w", name, show(r.Values[name]), err)
+		}
+
+		return
+	}
+
+	if env, ok := r.lookupEnv(name); ok {
+		if value, err = param.Format(env); err != nil {
+			return "", nil, fmt.Errorf("invalid parameter %q value from %s: %s; %w", name, EnvName(r.EnvPrefix, name), show(env), err)
+		}
+
+		r.set(name, value)
+		return
+	}
+
+	if ok, err = r.holds(name, def, conditions); err != nil {
+		return "", nil, err
+	} else if !ok {
+		if param, err = r.renderDefault(def, param); err != nil {
+			return "", nil, err
+		}
+		return param.String(), param, nil
+	}
+
+	if answer, ok := r.Answers[name]; ok {
+		// Prompt if the answer is no longer valid e.g., the parameter type changed.
+		if value, err := param.Format(answer); err == nil {
+			r.set(name, value)
+			return value, param, nil
+		}
+	}
+
+	// Render defaults only when needed since other parameters they reference may be prompted.
+	if param, err = r.renderDefault(def, param); err != nil {
+		return "", nil, err
+	}
+
+	if r.AcceptDefaults && def.hasDefault() {
+		if value, err = param.Format(param.String()); err != nil {
+			return "", nil, fmt.Errorf("invalid parameter %q default: %s; %w", name, show(param.String()), err)
+		}
+
+		r.set(name, value)
+		return
+	}
+
+	if r.NonInteractive || r.Prompter == nil && !r.IsTTY {
+		return "", nil, fmt.Errorf("cannot prompt for parameter %q", name)
+	}
+
+	prompt := &Prompt{
+		Name:     name,
+		Message:  name,
+		Help:     def.Help,
+		Validate: validator(param),
+	}
+	if def.Prompt != "" {
+		prompt.Message = strings.TrimRightFunc(def.Prompt, func(r rune) bool {
+			return r == '?'
+		})
+	}
+
+	var answer string
+	if answer, err = ask(ctx, prompter, prompt, param); err != nil {
+		return
+	}
+
+	// Make sure the Prompter validated the answer.
+	if value, err = param.Format(answer); err != nil {
+		return "", nil, fmt.Errorf("invalid parameter %q answer: %s; %w", name, show(answer), err)
+	}
+
+	r.set(name, value)
+	return
+}
+
+// value returns the paramValue for def including any validator.
+func (r *Resolver) value(def *Definition) (paramValue, error) {
+	param, err := def.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if validate := r.Validators[def.Name]; validate != nil {
+		param = &validatorValue{param, validate}
+	}
+	return param, nil
+}
+
+// renderDefault returns the paramValue for def with a string default rendered e.g.,
+// "github.com/{{github.owner}}/{{github.repo}}"; otherwise, it returns param.
+func (r *Resolver) renderDefault(def *Definition, param paramValue) (paramValue, error) {
+	s, ok := def.Default.(string)
+	if !ok || def.Type != "" && def.Type != "string" || r.Render == nil {
+		return param, nil
+	}
+
+	done, err := r.enter(def.Name, "default")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rendered, err := r.Render(s)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %q default %q: %w", def.Name, s, err)
+	} else if rendered == s {
+		return param, nil
+	}
+
+	// Definitions may be shared, so render a copy.
+	d := *def
+	d.Default = rendered
+	return r.value(&d)
+}
+
+// enter marks a parameter's condition or default as being evaluated, and returns a function to call when done.
+// An error is returned if it is already being evaluated.
+func (r *Resolver) enter(name, what string) (func(), error) {
+	if r.evaluating[name] {
+		return nil, fmt.Errorf("parameter %q %s depends on itself", name, what)
+	}
+	if r.evaluating == nil {
+		r.evaluating = make(map[string]bool)
+	}
+	r.evaluating[name] = true
+
+	return func() {
+		delete(r.evaluating, name)
+	}, nil
+}
+
+// set adds a formatted value to Values.
+func (r *Resolver) set(name, value string) {
+	if r.formatted == nil {
+		r.formatted = make(map[string]bool)
+	}
+	r.formatted[name] = true
+	r.Values[name] = value
+}
+
+// holds returns whether all conditions passed to param, and any condition declared by def, are true.
+func (r *Resolver) holds(name string, def *Definition, conditions []Condition) (bool, error) {
+	for _, condition := range conditions {
+		if !condition {
+			return false, nil
+		}
+	}
+
+	if def.When == "" || r.Evaluate == nil {
+		return true, nil
+	}
+
+	done, err := r.enter(name, "condition")
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	ok, err := r.Evaluate(def.When)
+	if err != nil {
+		return false, fmt.Errorf("parameter %q condition %q: %w", name, def.When, err)
+	}
+	return ok, nil
+}
+
+// validator returns a function that validates answers for a Prompt.
+func validator(param paramValue) func(string) error {
+	return func(answer string) error {
+		_, err := param.Format(answer)
+		return err
+	}
+}
+
+// ask prompts for a value of the parameter type and returns the answer formatted as a string.
+func ask(ctx context.Context, prompter Prompter, prompt *Prompt, param paramValue) (string, error) {
+	switch param := param.(type) {
+	case interface{ unwrap() paramValue }:
+		// Prompt for the type of value that validates answers.
+		return ask(ctx, prompter, prompt, param.unwrap())
+	case *intValue:
+		answer, err := prompter.Int(ctx, prompt, param.defaultValue)
+		return strconv.Itoa(answer), err
+	case *boolValue:
+		answer, err := prompter.Bool(ctx, prompt, param.defaultValue)
+		return strconv.FormatBool(answer), err
+	case *choiceValue:
+		return prompter.Choice(ctx, prompt, param.choices, param.defaultValue)
+	case *secretValue:
+		return prompter.Secret(ctx, prompt, param.defaultValue)
+	case *listValue:
+		if len(param.choices) == 0 {
+			return prompter.String(ctx, prompt, strings.Join(param.defaultValues, ", "))
+		}
+
+		answer, err := prompter.MultiChoice(ctx, prompt, param.choices, param.defaultValues)
+		if err != nil {
+			return "", err
+		}
+		return formatList(answer)
+	default:
+		return prompter.String(ctx, prompt, param.String())
+	}
 }
 
 func Pluralize(count int, thing string) string {
@@ -137,14 +421,131 @@ func Replace(from, to, source string) string {
 	return strings.Replace(source, from, to, -1)
 }
 
-func DeleteFunc(current *string, delete *bool, values *[]string) func(...string) string {
-	return func(str ...string) string {
-		*delete = true
+// DeleteFunc returns the deleteFile template function that deletes the current file, or files relative to root,
+// after all templates are executed.
+func DeleteFunc(root string, current *string, delete *bool, values *[]string) func(...string) (string, error) {
+	return func(str ...string) (string, error) {
 		if len(str) == 0 {
+			*delete = true
 			*values = append(*values, *current)
-		} else {
-			*values = append(*values, str...)
+			return "", nil
+		}
+
+		names := make([]string, len(str))
+		for i, name := range str {
+			var err error
+			if names[i], err = join(root, name); err != nil {
+				return "", err
+			}
+		}
+
+		*delete = true
+		*values = append(*values, names...)
+		return "", nil
+	}
+}
+
+// Move is a file to move after all templates are executed.
+type Move struct {
+	From string // The path of the file to move.
+	To   string // The path to which the file is moved.
+}
+
+// MoveFunc returns the moveFile template function that moves the current file to a path relative to root,
+// or moves a file relative to root to another path relative to root e.g., {{moveFile "LICENSE.mit" "LICENSE"}}.
+func MoveFunc(root string, current *string, values *[]Move) func(...string) (string, error) {
+	return func(str ...string) (string, error) {
+		from, to := *current, ""
+		switch len(str) {
+		case 1:
+			to = str[0]
+		case 2:
+			var err error
+			if from, err = join(root, str[0]); err != nil {
+				return "", err
+			}
+			to = str[1]
+		default:
+			return "", fmt.Errorf("expected 1 or 2 paths, got %d", len(str))
+		}
+
+		to, err := join(root, to)
+		if err != nil {
+			return "", err
+		}
+
+		*values = append(*values, Move{From: from, To: to})
+		return "", nil
+	}
+}
+
+// RenameFunc returns the renameFile template function that renames the current file, or a file relative
+// to root, in the same directory e.g., {{renameFile "README.md"}}.
+func RenameFunc(root string, current *string, values *[]Move) func(...string) (string, error) {
+	return func(str ...string) (string, error) {
+		from, name := *current, ""
+		switch len(str) {
+		case 1:
+			name = str[0]
+		case 2:
+			var err error
+			if from, err = join(root, str[0]); err != nil {
+				return "", err
+			}
+			name = str[1]
+		default:
+			return "", fmt.Errorf("expected 1 or 2 names, got %d", len(str))
+		}
+
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("invalid file name %q", name)
+		}
+
+		*values = append(*values, Move{From: from, To: path.Join(path.Dir(from), name)})
+		return "", nil
+	}
+}
+
+// File is a file written by emitFile.
+type File struct {
+	Path    string // The path of the file.
+	Content []byte // The rendered content.
+}
+
+// EmitFunc returns the emitFile template function that renders a template defined in the current file,
+// with optional data, into another file relative to root e.g., {{emitFile (printf "internal/%s/doc.go" .) "doc" .}}.
+// The execute function renders the named template.
+func EmitFunc(root string, execute func(name string, data any) ([]byte, error), values *[]File) func(string, string, ...any) (string, error) {
+	return func(name, tmpl string, data ...any) (string, error) {
+		if len(data) > 1 {
+			return "", fmt.Errorf("expected at most 1 data argument, got %d", len(data))
+		}
+
+		name, err := join(root, name)
+		if err != nil {
+			return "", err
+		}
+
+		var d any
+		if len(data) > 0 {
+			d = data[0]
+		}
+
+		content, err := execute(tmpl, d)
+		if err != nil {
+			return "", err
 		}
-		return ""
+
+		*values = append(*values, File{Path: name, Content: content})
+		return "", nil
+	}
+}
+
+// join joins root and a path that must be relative to root.
+func join(root, name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if name == "" || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+		return "", fmt.Errorf("path %q is not relative to the root", name)
 	}
+	return path.Join(root, clean), nil
 }
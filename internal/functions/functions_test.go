@@ -5,11 +5,17 @@ package functions
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/heaths/go-console"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/text/language"
 )
 
@@ -114,7 +120,12 @@ func TestParamFunc(t *testing.T) {
 		if tt.param != "" {
 			params["name"] = tt.param
 		}
-		sut := ParamFunc(con.Stdin(), con.Stderr(), con.IsStderrTTY(), params)
+		sut := ParamFunc(context.Background(), &Resolver{
+			Values: params,
+			Stdin:  con.Stdin(),
+			Stderr: con.Stderr(),
+			IsTTY:  con.IsStderrTTY(),
+		})
 
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := sut("name", tt.defaultValue, "What should I prompt?")
@@ -144,6 +155,834 @@ func TestParamFunc(t *testing.T) {
 	}
 }
 
+func TestParamFunc_canceled(t *testing.T) {
+	t.Parallel()
+
+	// The pipe is never written so reads block until canceled.
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sut := ParamFunc(ctx, &Resolver{
+		Values: make(map[string]string),
+		Stdin:  r,
+		Stderr: io.Discard,
+		IsTTY:  true,
+	})
+
+	cancel()
+	_, err := sut("name")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParamFunc_definitions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		def     *Definition
+		stdin   string
+		param   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			def:  &Definition{Name: "name", Default: "manifest", Prompt: "What is the name?", Help: "The project name."},
+			want: "manifest",
+		},
+		{
+			name:  "override",
+			def:   &Definition{Name: "name", Default: "manifest"},
+			stdin: "Earth",
+			want:  "Earth",
+		},
+		{
+			name:  "type",
+			def:   &Definition{Name: "name", Type: "int", Default: 1},
+			stdin: "world\n2",
+			want:  "2",
+		},
+		{
+			name:  "pattern",
+			def:   &Definition{Name: "name", Pattern: "^[a-z]+$"},
+			stdin: "Hello World\nhello",
+			want:  "hello",
+		},
+		{
+			name:    "pattern (supplied)",
+			def:     &Definition{Name: "name", Pattern: "^[a-z]+$"},
+			param:   "Hello World",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.def.Validate())
+
+			con := console.Fake(
+				console.WithStdin(bytes.NewBufferString(tt.stdin+"\n")),
+				console.WithStderrTTY(true),
+			)
+			_, stderr, _ := con.Buffers()
+
+			params := make(map[string]string)
+			if tt.param != "" {
+				params["name"] = tt.param
+			}
+
+			sut := ParamFunc(context.Background(), &Resolver{
+				Values:      params,
+				Definitions: map[string]*Definition{"name": tt.def},
+				Stdin:       con.Stdin(),
+				Stderr:      con.Stderr(),
+				IsTTY:       con.IsStderrTTY(),
+			})
+
+			// Definitions take precedence over arguments.
+			got, err := sut("name", "ignored", "Ignored?")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NotContains(t, stderr.String(), "Ignored")
+			if tt.def.Help != "" {
+				assert.Contains(t, stderr.String(), tt.def.Help)
+			}
+		})
+	}
+}
+
+func TestParamFunc_env(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"TEMPLATE_GITHUB_OWNER": "heaths",
+		"TEMPLATE_COPYRIGHT":    "invalid",
+	}
+
+	tests := []struct {
+		name     string
+		prefix   string
+		param    string
+		defValue any
+		values   map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:   "env",
+			prefix: "TEMPLATE",
+			param:  "github.owner",
+			want:   "heaths",
+		},
+		{
+			name:   "prefix with underscore",
+			prefix: "TEMPLATE_",
+			param:  "github.owner",
+			want:   "heaths",
+		},
+		{
+			name:   "supplied",
+			prefix: "TEMPLATE",
+			param:  "github.owner",
+			values: map[string]string{"github.owner": "supplied"},
+			want:   "supplied",
+		},
+		{
+			name:    "no prefix",
+			param:   "github.owner",
+			wantErr: true,
+		},
+		{
+			name:     "invalid",
+			prefix:   "TEMPLATE",
+			param:    "copyright",
+			defValue: 2022,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := tt.values
+			if values == nil {
+				values = make(map[string]string)
+			}
+
+			sut := ParamFunc(context.Background(), &Resolver{
+				Values:    values,
+				EnvPrefix: tt.prefix,
+				LookupEnv: func(name string) (string, bool) {
+					value, ok := env[name]
+					return value, ok
+				},
+				Stdin:  bytes.NewBufferString(""),
+				Stderr: io.Discard,
+			})
+
+			var args []any
+			if tt.defValue != nil {
+				args = append(args, tt.defValue)
+			}
+
+			got, err := sut(tt.param, args...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, values[tt.param])
+		})
+	}
+}
+
+func TestParamFunc_answers(t *testing.T) {
+	t.Parallel()
+
+	values := map[string]string{"name": "supplied"}
+	resolved := make(map[string]string)
+	sut := ParamFunc(context.Background(), &Resolver{
+		Values:   values,
+		Answers:  map[string]string{"name": "answer", "copyright": "2021", "invalid": "answer"},
+		Resolved: resolved,
+		Stdin:    bytes.NewBufferString("2022\n"),
+		Stderr:   io.Discard,
+		IsTTY:    true,
+	})
+
+	got, err := sut("name")
+	require.NoError(t, err)
+	assert.Equal(t, "supplied", got)
+
+	got, err = sut("copyright", 2020)
+	require.NoError(t, err)
+	assert.Equal(t, "2021", got)
+
+	// Invalid answers are prompted again.
+	got, err = sut("invalid", 2020)
+	require.NoError(t, err)
+	assert.Equal(t, "2022", got)
+
+	assert.Equal(t, map[string]string{"name": "supplied", "copyright": "2021", "invalid": "2022"}, resolved)
+}
+
+func TestParamFunc_nonInteractive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		args           []any
+		def            *Definition
+		param          string
+		acceptDefaults bool
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:           "default",
+			args:           []any{"world"},
+			acceptDefaults: true,
+			want:           "world",
+		},
+		{
+			name:           "default false",
+			args:           []any{false},
+			acceptDefaults: true,
+			want:           "",
+		},
+		{
+			name:           "no default",
+			acceptDefaults: true,
+			wantErr:        true,
+		},
+		{
+			name:           "empty default",
+			args:           []any{"", "What is the name?"},
+			acceptDefaults: true,
+			wantErr:        true,
+		},
+		{
+			name:           "supplied",
+			args:           []any{"world"},
+			param:          "Earth",
+			acceptDefaults: true,
+			want:           "Earth",
+		},
+		{
+			name:    "no input",
+			args:    []any{"world"},
+			wantErr: true,
+		},
+		{
+			name:           "definition",
+			def:            &Definition{Name: "name", Type: "int", Default: 2022},
+			acceptDefaults: true,
+			want:           "2022",
+		},
+		{
+			name:           "definition without default",
+			def:            &Definition{Name: "name", Type: "int"},
+			acceptDefaults: true,
+			wantErr:        true,
+		},
+		{
+			name:           "invalid default",
+			def:            &Definition{Name: "name", Default: "World", Pattern: "^[a-z]+$"},
+			acceptDefaults: true,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			resolver := &Resolver{
+				Values: make(map[string]string),
+				Stdin:  bytes.NewBufferString("ignored\n"),
+				Stderr: &stderr,
+				IsTTY:  true,
+
+				NonInteractive: true,
+				AcceptDefaults: tt.acceptDefaults,
+			}
+
+			if tt.param != "" {
+				resolver.Values["name"] = tt.param
+			}
+
+			if tt.def != nil {
+				require.NoError(t, tt.def.Validate())
+				resolver.Definitions = map[string]*Definition{"name": tt.def}
+			}
+
+			got, err := ParamFunc(context.Background(), resolver)("name", tt.args...)
+			assert.Empty(t, stderr.String(), "should not prompt")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// fakePrompter answers prompts with the next answer and records each prompt.
+type fakePrompter struct {
+	answers []any
+	prompts []*Prompt
+}
+
+func (p *fakePrompter) answer(prompt *Prompt) any {
+	p.prompts = append(p.prompts, prompt)
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	return answer
+}
+
+func (p *fakePrompter) String(ctx context.Context, prompt *Prompt, defaultValue string) (string, error) {
+	return p.answer(prompt).(string), nil
+}
+
+func (p *fakePrompter) Int(ctx context.Context, prompt *Prompt, defaultValue int) (int, error) {
+	return p.answer(prompt).(int), nil
+}
+
+func (p *fakePrompter) Bool(ctx context.Context, prompt *Prompt, defaultValue bool) (bool, error) {
+	return p.answer(prompt).(bool), nil
+}
+
+func (p *fakePrompter) Choice(ctx context.Context, prompt *Prompt, choices []string, defaultValue string) (string, error) {
+	return p.answer(prompt).(string), nil
+}
+
+func (p *fakePrompter) MultiChoice(ctx context.Context, prompt *Prompt, choices []string, defaultValues []string) ([]string, error) {
+	return p.answer(prompt).([]string), nil
+}
+
+func (p *fakePrompter) Secret(ctx context.Context, prompt *Prompt, defaultValue string) (string, error) {
+	return p.answer(prompt).(string), nil
+}
+
+func TestParamFunc_prompter(t *testing.T) {
+	t.Parallel()
+
+	prompter := &fakePrompter{
+		answers: []any{"Earth", 2023, false, "Hello World"},
+	}
+
+	// Prompters are used even if Stderr is not a terminal.
+	sut := ParamFunc(context.Background(), &Resolver{
+		Values: make(map[string]string),
+		Definitions: map[string]*Definition{
+			"pattern": {Name: "pattern", Default: "", Pattern: "^[a-z]+$"},
+		},
+		Prompter: prompter,
+	})
+
+	got, err := sut("name", "world", "What is the name?")
+	require.NoError(t, err)
+	assert.Equal(t, "Earth", got)
+
+	got, err = sut("year", 2022)
+	require.NoError(t, err)
+	assert.Equal(t, "2023", got)
+
+	got, err = sut("release", true)
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	// Answers the Prompter did not validate are returned as errors.
+	_, err = sut("pattern")
+	assert.EqualError(t, err, `invalid parameter "pattern" answer: Hello World; expected a string matching ^[a-z]+$`)
+
+	require.Len(t, prompter.prompts, 4)
+	assert.Equal(t, "What is the name", prompter.prompts[0].Message)
+	assert.Equal(t, "year", prompter.prompts[1].Message)
+	assert.NoError(t, prompter.prompts[3].Validate("hello"))
+	assert.EqualError(t, prompter.prompts[3].Validate("Hello World"), "expected a string matching ^[a-z]+$")
+}
+
+func TestParamFunc_choice(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		def     *Definition
+		stdin   string
+		param   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "default",
+			stdin: "\n",
+			want:  "mit",
+		},
+		{
+			name:  "prompt",
+			stdin: "bsd\nApache-2.0\n",
+			want:  "apache-2.0",
+		},
+		{
+			name:  "supplied",
+			param: "APACHE-2.0",
+			want:  "apache-2.0",
+		},
+		{
+			name:    "invalid supplied",
+			param:   "bsd",
+			wantErr: true,
+		},
+		{
+			name:  "definition",
+			def:   &Definition{Name: "license", Choices: []string{"mit", "apache-2.0"}, Default: "apache-2.0"},
+			stdin: "\n",
+			want:  "apache-2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &Resolver{
+				Values: make(map[string]string),
+				Stdin:  bytes.NewBufferString(tt.stdin),
+				Stderr: io.Discard,
+				IsTTY:  true,
+			}
+
+			if tt.param != "" {
+				resolver.Values["license"] = tt.param
+			}
+
+			if tt.def != nil {
+				require.NoError(t, tt.def.Validate())
+				resolver.Definitions = map[string]*Definition{"license": tt.def}
+			}
+
+			got, err := ParamFunc(context.Background(), resolver)("license", Choices{"mit", "apache-2.0"})
+			if tt.wantErr {
+				assert.EqualError(t, err, `invalid parameter "license" value: bsd; expected one of mit, apache-2.0`)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParamFunc_list(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		def     *Definition
+		stdin   string
+		param   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "default",
+			stdin: "\n",
+			want:  []string{"linux", "windows"},
+		},
+		{
+			name:  "prompt",
+			stdin: "bsd\n2, Linux\n",
+			want:  []string{"windows", "linux"},
+		},
+		{
+			name:  "supplied",
+			param: "Windows,linux",
+			want:  []string{"windows", "linux"},
+		},
+		{
+			name:  "supplied json",
+			param: `["linux"]`,
+			want:  []string{"linux"},
+		},
+		{
+			name:  "supplied empty",
+			param: "[]",
+			want:  []string{},
+		},
+		{
+			name:    "invalid supplied",
+			param:   "bsd",
+			wantErr: true,
+		},
+		{
+			name:  "definition",
+			def:   &Definition{Name: "platforms", Type: "list", Choices: []string{"linux", "windows", "darwin"}, Default: []any{"darwin"}},
+			stdin: "\n",
+			want:  []string{"darwin"},
+		},
+		{
+			name:  "definition without choices",
+			def:   &Definition{Name: "platforms", Type: "list"},
+			stdin: "freebsd, openbsd\n",
+			want:  []string{"freebsd", "openbsd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &Resolver{
+				Values:   make(map[string]string),
+				Resolved: make(map[string]string),
+				Stdin:    bytes.NewBufferString(tt.stdin),
+				Stderr:   io.Discard,
+				IsTTY:    true,
+			}
+
+			if tt.param != "" {
+				resolver.Values["platforms"] = tt.param
+			}
+
+			if tt.def != nil {
+				require.NoError(t, tt.def.Validate())
+				resolver.Definitions = map[string]*Definition{"platforms": tt.def}
+			}
+
+			got, err := ParamFunc(context.Background(), resolver)("platforms", List{"linux", "windows"})
+			if tt.wantErr {
+				assert.EqualError(t, err, `invalid parameter "platforms" value: bsd; expected any of linux, windows`)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// Lists are saved as JSON arrays.
+			want, err := formatList(tt.want)
+			require.NoError(t, err)
+			assert.Equal(t, want, resolver.Resolved["platforms"])
+		})
+	}
+}
+
+func TestParamFunc_multiChoice(t *testing.T) {
+	t.Parallel()
+
+	prompter := &fakePrompter{
+		answers: []any{[]string{"windows"}},
+	}
+
+	sut := ParamFunc(context.Background(), &Resolver{
+		Values:   make(map[string]string),
+		Prompter: prompter,
+	})
+
+	got, err := sut("platforms", List{"linux", "windows"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"windows"}, got)
+
+	require.Len(t, prompter.prompts, 1)
+	assert.NoError(t, prompter.prompts[0].Validate(`["linux"]`))
+	assert.EqualError(t, prompter.prompts[0].Validate(`["bsd"]`), "expected any of linux, windows")
+}
+
+func TestParamFunc_secret(t *testing.T) {
+	t.Parallel()
+
+	prompter := &fakePrompter{
+		answers: []any{"s3cr3t", "Hello World"},
+	}
+
+	resolver := &Resolver{
+		Values: make(map[string]string),
+		Definitions: map[string]*Definition{
+			"pattern": {Name: "pattern", Type: "secret", Pattern: "^[a-z]+$"},
+		},
+		Resolved: make(map[string]string),
+		Secrets:  make(map[string]string),
+		Prompter: prompter,
+	}
+	require.NoError(t, resolver.Definitions["pattern"].Validate())
+	sut := ParamFunc(context.Background(), resolver)
+
+	got, err := sut("token", Secret(""))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", got)
+
+	// Secrets are never shown in errors.
+	_, err = sut("pattern")
+	assert.EqualError(t, err, `invalid parameter "pattern" answer: ********; expected a secret matching ^[a-z]+$`)
+
+	// Later references without a default are still secret.
+	got, err = sut("token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", got)
+
+	// Earlier references without a default are no longer resolved once secret.
+	resolver.Values["key"] = "k3y"
+	_, err = sut("key")
+	require.NoError(t, err)
+	assert.Equal(t, "k3y", resolver.Resolved["key"])
+
+	_, err = sut("key", Secret(""))
+	require.NoError(t, err)
+
+	assert.Empty(t, resolver.Resolved)
+	assert.Equal(t, map[string]string{"token": "s3cr3t", "key": "k3y"}, resolver.Secrets)
+}
+
+func TestParamFunc_validators(t *testing.T) {
+	t.Parallel()
+
+	prompter := &fakePrompter{
+		answers: []any{"go template", 0},
+	}
+
+	resolver := &Resolver{
+		Values: map[string]string{"name": "Go Template", "os": "linux,windows"},
+		Definitions: map[string]*Definition{
+			"year": {Name: "year", Default: 2022, Min: intPtr(1970), Max: intPtr(2100)},
+		},
+		Validators: map[string]func(string) error{
+			"name": func(s string) error {
+				if strings.ToLower(s) != s {
+					return errors.New("expected lowercase")
+				}
+				return nil
+			},
+			"os": func(s string) error {
+				if !strings.Contains(s, `"linux"`) {
+					return errors.New("expected linux")
+				}
+				return nil
+			},
+		},
+		Prompter: prompter,
+	}
+	require.NoError(t, resolver.Definitions["year"].Validate())
+	sut := ParamFunc(context.Background(), resolver)
+
+	// Supplied values are validated.
+	_, err := sut("name")
+	assert.EqualError(t, err, `invalid parameter "name" value: Go Template; expected lowercase`)
+
+	// Answers are validated.
+	delete(resolver.Values, "name")
+	_, err = sut("name")
+	require.NoError(t, err)
+
+	_, err = sut("year")
+	assert.EqualError(t, err, `invalid parameter "year" answer: 0; expected an integer of 1970 to 2100`)
+
+	// Validated lists are still returned as lists.
+	got, err := sut("os", List{"linux", "windows", "darwin"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux", "windows"}, got)
+
+	resolver.Values["os"] = "darwin"
+	_, err = sut("os", List{"linux", "windows", "darwin"})
+	assert.EqualError(t, err, `invalid parameter "os" value: darwin; expected linux`)
+
+	require.Len(t, prompter.prompts, 2)
+	assert.NoError(t, prompter.prompts[0].Validate("go-template"))
+	assert.EqualError(t, prompter.prompts[0].Validate("Go"), "expected lowercase")
+	assert.EqualError(t, prompter.prompts[1].Validate("1969"), "expected an integer of 1970 to 2100")
+}
+
+func TestParamFunc_conditions(t *testing.T) {
+	t.Parallel()
+
+	prompter := &fakePrompter{
+		answers: []any{"ghcr.io", false},
+	}
+
+	resolver := &Resolver{
+		Values: make(map[string]string),
+		Definitions: map[string]*Definition{
+			"docker.tag": {Name: "docker.tag", Default: "latest", When: "docker"},
+			"loop":       {Name: "loop", When: "loop"},
+		},
+		Resolved: make(map[string]string),
+		Prompter: prompter,
+	}
+	for _, def := range resolver.Definitions {
+		require.NoError(t, def.Validate())
+	}
+
+	var sut func(string, ...any) (any, error)
+	resolver.Evaluate = func(pipeline string) (bool, error) {
+		value, err := sut(pipeline)
+		return value != "", err
+	}
+	sut = ParamFunc(context.Background(), resolver)
+
+	// Skipped parameters use their default without prompting.
+	got, err := sut("docker.registry", "docker.io", When(""))
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io", got)
+
+	got, err = sut("docker.registry", "docker.io", "Which registry?", When("true"))
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io", got)
+
+	resolver.Values["docker"] = ""
+	got, err = sut("docker.tag")
+	require.NoError(t, err)
+	assert.Equal(t, "latest", got)
+
+	_, err = sut("loop")
+	assert.EqualError(t, err, `parameter "loop" condition "loop": parameter "loop" condition depends on itself`)
+
+	// Conditions may resolve parameters more than once, so false must not become the default.
+	for i := 0; i < 2; i++ {
+		got, err = sut("release", true)
+		require.NoError(t, err)
+		assert.Equal(t, "", got)
+	}
+
+	require.Len(t, prompter.prompts, 2)
+	assert.Equal(t, "Which registry", prompter.prompts[0].Message)
+	assert.Equal(t, map[string]string{"docker.registry": "ghcr.io", "docker": "", "release": "false"}, resolver.Resolved)
+}
+
+func TestParamFunc_renderDefault(t *testing.T) {
+	t.Parallel()
+
+	var stderr bytes.Buffer
+	resolver := &Resolver{
+		Values: map[string]string{"github.owner": "heaths"},
+		Definitions: map[string]*Definition{
+			"module": {Name: "module", Default: "github.com/{{github.owner}}"},
+		},
+		Stdin:  bytes.NewBufferString("\n"),
+		Stderr: &stderr,
+		IsTTY:  true,
+		Render: func(s string) (string, error) {
+			return strings.ReplaceAll(s, "{{github.owner}}", "heaths"), nil
+		},
+	}
+	require.NoError(t, resolver.Definitions["module"].Validate())
+	sut := ParamFunc(context.Background(), resolver)
+
+	// Prompts show the rendered default.
+	got, err := sut("module")
+	require.NoError(t, err)
+	assert.Equal(t, "github.com/heaths", got)
+	assert.Contains(t, stderr.String(), "[github.com/heaths]")
+
+	// Defaults passed to param are rendered.
+	resolver.AcceptDefaults = true
+	got, err = sut("description", "{{github.owner}}'s project")
+	require.NoError(t, err)
+	assert.Equal(t, "heaths's project", got)
+
+	// Supplied values are not rendered.
+	resolver.Values["name"] = "{{github.owner}}"
+	got, err = sut("name", "")
+	require.NoError(t, err)
+	assert.Equal(t, "{{github.owner}}", got)
+}
+
+func TestParamFunc_defaults(t *testing.T) {
+	t.Parallel()
+
+	var stderr bytes.Buffer
+	resolver := &Resolver{
+		Values: map[string]string{"git.email": "heaths@example.com"},
+		Defaults: map[string]string{
+			"git.name":     "Heath Stewart",
+			"git.email":    "heaths@example.org",
+			"github.owner": "heaths",
+			"github.repo":  "go-template",
+		},
+		Stdin:  bytes.NewBufferString("\n"),
+		Stderr: &stderr,
+		IsTTY:  true,
+	}
+	sut := ParamFunc(context.Background(), resolver)
+
+	// Prompts show the default.
+	got, err := sut("git.name")
+	require.NoError(t, err)
+	assert.Equal(t, "Heath Stewart", got)
+	assert.Contains(t, stderr.String(), "[Heath Stewart]")
+
+	// Supplied values take precedence.
+	got, err = sut("git.email")
+	require.NoError(t, err)
+	assert.Equal(t, "heaths@example.com", got)
+
+	// Defaults passed to param take precedence.
+	resolver.AcceptDefaults = true
+	got, err = sut("github.owner", "octocat")
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", got)
+
+	got, err = sut("github.repo", "")
+	require.NoError(t, err)
+	assert.Equal(t, "go-template", got)
+
+	// Only string parameters are defaulted.
+	resolver.Defaults["private"] = "true"
+	got, err = sut("private", false)
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+}
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "TEMPLATE_GITHUB_OWNER", EnvName("TEMPLATE", "github.owner"))
+	assert.Equal(t, "TEMPLATE_GO_VERSION", EnvName("TEMPLATE_", "go-version"))
+	assert.Equal(t, "NAME", EnvName("", "name"))
+}
+
 func TestPluralize(t *testing.T) {
 	t.Parallel()
 
@@ -306,23 +1145,123 @@ func TestReplace(t *testing.T) {
 	assert.Equal(t, "my_crate", sut)
 }
 
+func TestMove(t *testing.T) {
+	t.Parallel()
+
+	current := "src/README.template.md"
+	var values []Move
+	sut := MoveFunc("src", &current, &values)
+
+	got, err := sut("docs/README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	_, err = sut("LICENSE.mit", "./LICENSE")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Move{
+		{From: "src/README.template.md", To: "src/docs/README.md"},
+		{From: "src/LICENSE.mit", To: "src/LICENSE"},
+	}, values)
+
+	_, err = sut("../README.md")
+	assert.EqualError(t, err, `path "../README.md" is not relative to the root`)
+
+	_, err = sut("/README.md", "README.md")
+	assert.EqualError(t, err, `path "/README.md" is not relative to the root`)
+
+	_, err = sut()
+	assert.EqualError(t, err, "expected 1 or 2 paths, got 0")
+	assert.Len(t, values, 2)
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	current := "docs/README.template.md"
+	var values []Move
+	sut := RenameFunc(".", &current, &values)
+
+	got, err := sut("README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	_, err = sut("licenses/MIT.txt", "LICENSE")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Move{
+		{From: "docs/README.template.md", To: "docs/README.md"},
+		{From: "licenses/MIT.txt", To: "licenses/LICENSE"},
+	}, values)
+
+	_, err = sut("../README.md")
+	assert.EqualError(t, err, `invalid file name "../README.md"`)
+
+	_, err = sut("..")
+	assert.EqualError(t, err, `invalid file name ".."`)
+
+	_, err = sut("a", "b", "c")
+	assert.EqualError(t, err, "expected 1 or 2 names, got 3")
+	assert.Len(t, values, 2)
+}
+
+func TestEmit(t *testing.T) {
+	t.Parallel()
+
+	var values []File
+	sut := EmitFunc("src", func(name string, data any) ([]byte, error) {
+		if name != "doc" {
+			return nil, errors.New("undefined")
+		}
+		return []byte(fmt.Sprintf("package %v", data)), nil
+	}, &values)
+
+	got, err := sut("internal/a/doc.go", "doc", "a")
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	_, err = sut("internal/nil/doc.go", "doc")
+	require.NoError(t, err)
+
+	assert.Equal(t, []File{
+		{Path: "src/internal/a/doc.go", Content: []byte("package a")},
+		{Path: "src/internal/nil/doc.go", Content: []byte("package <nil>")},
+	}, values)
+
+	_, err = sut("internal/b/doc.go", "undefined")
+	assert.EqualError(t, err, "undefined")
+
+	_, err = sut("../doc.go", "doc")
+	assert.EqualError(t, err, `path "../doc.go" is not relative to the root`)
+
+	_, err = sut("doc.go", "doc", "a", "b")
+	assert.EqualError(t, err, "expected at most 1 data argument, got 2")
+	assert.Len(t, values, 2)
+}
+
 func TestDelete(t *testing.T) {
 	t.Parallel()
 
-	current := "current"
+	current := "src/current"
 	var delete bool
 	var values []string
-	sut := DeleteFunc(&current, &delete, &values)
+	sut := DeleteFunc("src", &current, &delete, &values)
 
-	assert.Equal(t, "", sut())
+	got, err := sut()
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
 	assert.True(t, delete)
-	assert.Equal(t, []string{"current"}, values)
+	assert.Equal(t, []string{"src/current"}, values)
 
-	assert.Equal(t, "", sut("foo", "bar"))
-	assert.True(t, delete)
-	assert.Equal(t, []string{"current", "foo", "bar"}, values)
+	_, err = sut("foo", "bar")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/current", "src/foo", "src/bar"}, values)
 
-	assert.Equal(t, "", sut("baz"))
-	assert.True(t, delete)
-	assert.Equal(t, []string{"current", "foo", "bar", "baz"}, values)
+	_, err = sut("./baz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/current", "src/foo", "src/bar", "src/baz"}, values)
+
+	_, err = sut("qux", "../README.md")
+	assert.EqualError(t, err, `path "../README.md" is not relative to the root`)
+	assert.Len(t, values, 4)
 }
@@ -0,0 +1,179 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyBackspace = 0x08
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// selectChoice shows a menu of choices starting with selected, and moves the selection with arrow keys
+// or "j" and "k" read from keys until Enter is pressed or ctx is done. The terminal must already be in raw mode.
+func selectChoice(ctx context.Context, keys *keyReader, w io.Writer, message string, choices []string, selected int) (int, error) {
+	fmt.Fprintf(w, "\033[32m%s?\033[0m\r\n", message)
+	draw := func() {
+		for i, choice := range choices {
+			if i == selected {
+				fmt.Fprintf(w, "\r\033[K\033[36m> %s\033[0m\r\n", choice)
+			} else {
+				fmt.Fprintf(w, "\r\033[K  %s\r\n", choice)
+			}
+		}
+	}
+	draw()
+
+	for {
+		key, err := keys.ReadKey(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		switch key {
+		case '\r', '\n':
+			// Replace the menu with the answer.
+			fmt.Fprintf(w, "\033[%dA\r\033[J\033[32m%s? \033[0m%s\r\n", len(choices)+1, message, choices[selected])
+			return selected, nil
+		case keyCtrlC, keyCtrlD:
+			fmt.Fprint(w, "\r\n")
+			return 0, context.Canceled
+		case 'k':
+			selected--
+		case 'j':
+			selected++
+		case keyEscape:
+			if next, _ := keys.ReadKey(ctx); next != '[' {
+				continue
+			}
+			switch next, _ := keys.ReadKey(ctx); next {
+			case 'A':
+				selected--
+			case 'B':
+				selected++
+			}
+		default:
+			continue
+		}
+
+		// Wrap around either end of the menu.
+		selected = (selected + len(choices)) % len(choices)
+
+		fmt.Fprintf(w, "\033[%dA", len(choices))
+		draw()
+	}
+}
+
+// selectChoices shows a checklist of choices, moves the cursor with arrow keys or "j" and "k",
+// and toggles the choice under the cursor with Space until Enter is pressed or ctx is done.
+// The terminal must already be in raw mode.
+func selectChoices(ctx context.Context, keys *keyReader, w io.Writer, message string, choices []string, selected []bool) ([]bool, error) {
+	selected = append([]bool(nil), selected...)
+	cursor := 0
+
+	fmt.Fprintf(w, "\033[32m%s? \033[90m[Space to select]\033[0m\r\n", message)
+	draw := func() {
+		for i, choice := range choices {
+			check := " "
+			if selected[i] {
+				check = "x"
+			}
+			if i == cursor {
+				fmt.Fprintf(w, "\r\033[K\033[36m> [%s] %s\033[0m\r\n", check, choice)
+			} else {
+				fmt.Fprintf(w, "\r\033[K  [%s] %s\r\n", check, choice)
+			}
+		}
+	}
+	draw()
+
+	for {
+		key, err := keys.ReadKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case '\r', '\n':
+			// Replace the checklist with the answer.
+			var answer []string
+			for i, choice := range choices {
+				if selected[i] {
+					answer = append(answer, choice)
+				}
+			}
+			fmt.Fprintf(w, "\033[%dA\r\033[J\033[32m%s? \033[0m%s\r\n", len(choices)+1, message, strings.Join(answer, ", "))
+			return selected, nil
+		case keyCtrlC, keyCtrlD:
+			fmt.Fprint(w, "\r\n")
+			return nil, context.Canceled
+		case ' ':
+			selected[cursor] = !selected[cursor]
+		case 'k':
+			cursor--
+		case 'j':
+			cursor++
+		case keyEscape:
+			if next, _ := keys.ReadKey(ctx); next != '[' {
+				continue
+			}
+			switch next, _ := keys.ReadKey(ctx); next {
+			case 'A':
+				cursor--
+			case 'B':
+				cursor++
+			}
+		default:
+			continue
+		}
+
+		// Wrap around either end of the checklist.
+		cursor = (cursor + len(choices)) % len(choices)
+
+		fmt.Fprintf(w, "\033[%dA", len(choices))
+		draw()
+	}
+}
+
+// readSecret reads keys until Enter is pressed or ctx is done without echoing them, and returns what was typed.
+// The terminal must already be in raw mode.
+func readSecret(ctx context.Context, keys *keyReader, w io.Writer) (string, error) {
+	var answer []rune
+	for {
+		key, err := keys.ReadKey(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			fmt.Fprint(w, "\r\n")
+			return string(answer), nil
+		case keyCtrlC, keyCtrlD:
+			fmt.Fprint(w, "\r\n")
+			return "", context.Canceled
+		case keyBackspace, keyDelete:
+			if len(answer) > 0 {
+				answer = answer[:len(answer)-1]
+			}
+		case keyEscape:
+			// Ignore escape sequences like arrow keys.
+			if next, _ := keys.ReadKey(ctx); next == '[' {
+				keys.ReadKey(ctx)
+			}
+		default:
+			if key >= ' ' {
+				answer = append(answer, key)
+			}
+		}
+	}
+}
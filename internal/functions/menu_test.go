@@ -0,0 +1,217 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectChoice(t *testing.T) {
+	t.Parallel()
+
+	choices := []string{"mit", "apache-2.0", "bsd"}
+	tests := []struct {
+		name     string
+		keys     string
+		selected int
+		want     int
+		wantErr  error
+	}{
+		{
+			name: "enter",
+			keys: "\r",
+			want: 0,
+		},
+		{
+			name:     "default",
+			keys:     "\r",
+			selected: 2,
+			want:     2,
+		},
+		{
+			name: "down",
+			keys: "\x1b[B\x1b[B\r",
+			want: 2,
+		},
+		{
+			name: "up wraps",
+			keys: "\x1b[A\r",
+			want: 2,
+		},
+		{
+			name:     "down wraps",
+			keys:     "j\r",
+			selected: 2,
+			want:     0,
+		},
+		{
+			name: "ignored",
+			keys: "x\x1b[C\r",
+			want: 0,
+		},
+		{
+			name:    "ctrl+c",
+			keys:    "\x03",
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "eof",
+			keys:    "j",
+			wantErr: io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			got, err := selectChoice(context.Background(), newKeyReader(bytes.NewBufferString(tt.keys)), &w, "license", choices, tt.selected)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, w.String(), "license? \033[0m"+choices[tt.want])
+		})
+	}
+}
+
+func TestSelectChoices(t *testing.T) {
+	t.Parallel()
+
+	choices := []string{"linux", "windows", "darwin"}
+	tests := []struct {
+		name     string
+		keys     string
+		selected []bool
+		want     []bool
+		wantErr  error
+	}{
+		{
+			name:     "enter",
+			keys:     "\r",
+			selected: []bool{true, false, true},
+			want:     []bool{true, false, true},
+		},
+		{
+			name:     "toggle",
+			keys:     " \x1b[B \r",
+			selected: []bool{true, false, false},
+			want:     []bool{false, true, false},
+		},
+		{
+			name:     "up wraps",
+			keys:     "k \r",
+			selected: []bool{false, false, false},
+			want:     []bool{false, false, true},
+		},
+		{
+			name:     "ctrl+c",
+			keys:     " \x03",
+			selected: []bool{false, false, false},
+			wantErr:  context.Canceled,
+		},
+		{
+			name:     "eof",
+			keys:     " ",
+			selected: []bool{false, false, false},
+			wantErr:  io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			selected := append([]bool(nil), tt.selected...)
+			got, err := selectChoices(context.Background(), newKeyReader(bytes.NewBufferString(tt.keys)), &w, "platforms", choices, selected)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.selected, selected, "selected should not be modified")
+		})
+	}
+}
+
+func TestReadSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		keys    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "enter",
+			keys: "s3cr3t\r",
+			want: "s3cr3t",
+		},
+		{
+			name: "backspace",
+			keys: "s3cr3x\x7ft\x08\x08\x08\x08\x08\x08\x08p\u00e4ss\r",
+			want: "p\u00e4ss",
+		},
+		{
+			name: "ignored",
+			keys: "a\x1b[Ab\tc\n",
+			want: "abc",
+		},
+		{
+			name:    "ctrl+c",
+			keys:    "s3\x03",
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "eof",
+			keys:    "s3",
+			wantErr: io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			got, err := readSecret(context.Background(), newKeyReader(bytes.NewBufferString(tt.keys)), &w)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NotContains(t, w.String(), tt.want, "secret should not be echoed")
+		})
+	}
+}
+
+func TestMenus_canceled(t *testing.T) {
+	t.Parallel()
+
+	// The pipe is never written so reads block until canceled.
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	keys := newKeyReader(r)
+	cancel()
+
+	_, err := selectChoice(ctx, keys, io.Discard, "license", []string{"mit"}, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = selectChoices(ctx, keys, io.Discard, "platforms", []string{"linux"}, []bool{false})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = readSecret(ctx, keys, io.Discard)
+	assert.ErrorIs(t, err, context.Canceled)
+}
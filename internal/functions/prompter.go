@@ -0,0 +1,314 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/term"
+)
+
+// Prompter prompts users for parameter values.
+// Each method returns the answer, or the default if the user did not answer.
+// If Prompt.Validate returns an error, implementations should show the error and prompt again.
+type Prompter interface {
+	String(ctx context.Context, prompt *Prompt, defaultValue string) (string, error)
+	Int(ctx context.Context, prompt *Prompt, defaultValue int) (int, error)
+	Bool(ctx context.Context, prompt *Prompt, defaultValue bool) (bool, error)
+	Choice(ctx context.Context, prompt *Prompt, choices []string, defaultValue string) (string, error)
+	MultiChoice(ctx context.Context, prompt *Prompt, choices []string, defaultValues []string) ([]string, error)
+	Secret(ctx context.Context, prompt *Prompt, defaultValue string) (string, error) // Should not echo the answer or show the default.
+}
+
+// Prompt describes what to prompt for.
+type Prompt struct {
+	Name     string             // The parameter name.
+	Message  string             // The prompt without a trailing "?"; otherwise, Name.
+	Help     string             // Optional help to display before the prompt.
+	Validate func(string) error // Validates an answer formatted as a string or JSON array of strings, or nil if no validation is needed.
+}
+
+func (p *Prompt) validate(answer string) error {
+	if p.Validate == nil {
+		return nil
+	}
+	return p.Validate(answer)
+}
+
+// message returns the Message with the Name if different.
+func (p *Prompt) message() string {
+	if p.Message != p.Name {
+		return fmt.Sprintf("%s (%s)", p.Message, p.Name)
+	}
+	return p.Message
+}
+
+// ttyPrompter prompts on a terminal using ANSI escape codes.
+type ttyPrompter struct {
+	r    *lineReader
+	w    io.Writer
+	in   *os.File   // The input if a terminal that can show menus.
+	keys *keyReader // Reads keys from in.
+}
+
+func newTTYPrompter(r io.Reader, w io.Writer) *ttyPrompter {
+	p := &ttyPrompter{
+		r: newLineReader(r),
+		w: w,
+	}
+
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.in = f
+		p.keys = newKeyReader(f)
+	}
+
+	return p
+}
+
+func (p *ttyPrompter) String(ctx context.Context, prompt *Prompt, defaultValue string) (string, error) {
+	return p.ask(ctx, prompt, defaultValue, defaultValue, nil)
+}
+
+func (p *ttyPrompter) Int(ctx context.Context, prompt *Prompt, defaultValue int) (int, error) {
+	def := strconv.Itoa(defaultValue)
+	answer, err := p.ask(ctx, prompt, def, def, func(s string) (string, error) {
+		if _, err := strconv.ParseInt(s, 10, 32); err != nil {
+			return "", errors.New("expected an integer")
+		}
+		return s, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(answer)
+}
+
+func (p *ttyPrompter) Bool(ctx context.Context, prompt *Prompt, defaultValue bool) (bool, error) {
+	display := "y/N"
+	if defaultValue {
+		display = "Y/n"
+	}
+
+	answer, err := p.ask(ctx, prompt, display, strconv.FormatBool(defaultValue), func(s string) (string, error) {
+		if strings.EqualFold(s, "y") || strings.EqualFold(s, "yes") || strings.EqualFold(s, "true") {
+			return "true", nil
+		}
+		if strings.EqualFold(s, "n") || strings.EqualFold(s, "no") || strings.EqualFold(s, "false") {
+			return "false", nil
+		}
+		return "", errors.New("expected yes (Y) or no (N)")
+	})
+	if err != nil {
+		return false, err
+	}
+	return answer == "true", nil
+}
+
+func (p *ttyPrompter) Choice(ctx context.Context, prompt *Prompt, choices []string, defaultValue string) (string, error) {
+	if p.in != nil {
+		return p.menu(ctx, prompt, choices, defaultValue)
+	}
+
+	return p.ask(ctx, prompt, strings.Join(choices, "/"), defaultValue, func(s string) (string, error) {
+		for i, choice := range choices {
+			if strings.EqualFold(s, choice) || s == strconv.Itoa(i+1) {
+				return choice, nil
+			}
+		}
+		return "", fmt.Errorf("expected one of %s", strings.Join(choices, ", "))
+	})
+}
+
+func (p *ttyPrompter) MultiChoice(ctx context.Context, prompt *Prompt, choices []string, defaultValues []string) ([]string, error) {
+	var answer string
+	var err error
+	if p.in != nil {
+		answer, err = p.checklist(ctx, prompt, choices, defaultValues)
+	} else {
+		var def string
+		if def, err = formatList(defaultValues); err != nil {
+			return nil, err
+		}
+		answer, err = p.ask(ctx, prompt, strings.Join(defaultValues, ", "), def, func(s string) (string, error) {
+			var values []string
+		values:
+			for _, value := range strings.Split(s, ",") {
+				if value = strings.TrimSpace(value); value == "" {
+					continue
+				}
+				for i, choice := range choices {
+					if strings.EqualFold(value, choice) || value == strconv.Itoa(i+1) {
+						values = append(values, choice)
+						continue values
+					}
+				}
+				return "", fmt.Errorf("expected any of %s", strings.Join(choices, ", "))
+			}
+			return formatList(values)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseList(answer)
+}
+
+func (p *ttyPrompter) Secret(ctx context.Context, prompt *Prompt, defaultValue string) (string, error) {
+	var display string
+	if defaultValue != "" {
+		display = Mask
+	}
+
+	// Input that is not from a terminal is not echoed anyway.
+	read := p.r.ReadLine
+	if p.in != nil {
+		read = p.readSecret
+	}
+	return p.askWith(ctx, prompt, display, defaultValue, read, nil)
+}
+
+// ask prompts until the user enters a valid answer, or accepts a valid default by entering nothing.
+// If parse is not nil, it converts an answer before it is validated.
+func (p *ttyPrompter) ask(ctx context.Context, prompt *Prompt, display, defaultValue string, parse func(string) (string, error)) (string, error) {
+	return p.askWith(ctx, prompt, display, defaultValue, p.r.ReadLine, parse)
+}
+
+// askWith is like ask but reads answers with read.
+func (p *ttyPrompter) askWith(ctx context.Context, prompt *Prompt, display, defaultValue string, read func(context.Context) (string, error), parse func(string) (string, error)) (string, error) {
+	message := prompt.message()
+	if prompt.Help != "" {
+		fmt.Fprintf(p.w, "\033[90m%s\033[0m\n", prompt.Help)
+	}
+
+	for {
+		// Assume color support since we're on a TTY.
+		fmt.Fprintf(p.w, "\033[32m%s? \033[90m[%s]\033[0m: ", message, display)
+
+		answer, err := read(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = defaultValue
+		} else if parse != nil {
+			answer, err = parse(answer)
+		}
+
+		if err == nil {
+			if err = prompt.validate(answer); err == nil {
+				return answer, nil
+			}
+		}
+
+		fmt.Fprintf(p.w, "\033[31m%s. Please try again.\033[0m\n", capitalize(err.Error()))
+	}
+}
+
+// menu shows a menu of choices that can be selected with arrow keys.
+func (p *ttyPrompter) menu(ctx context.Context, prompt *Prompt, choices []string, defaultValue string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if prompt.Help != "" {
+		fmt.Fprintf(p.w, "\033[90m%s\033[0m\n", prompt.Help)
+	}
+
+	state, err := term.MakeRaw(int(p.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(p.in.Fd()), state)
+
+	selected := slices.Index(choices, defaultValue)
+	if selected < 0 {
+		selected = 0
+	}
+
+	for {
+		if selected, err = selectChoice(ctx, p.keys, p.w, prompt.message(), choices, selected); err != nil {
+			return "", err
+		}
+
+		if err = prompt.validate(choices[selected]); err == nil {
+			return choices[selected], nil
+		}
+		fmt.Fprintf(p.w, "\033[31m%s. Please try again.\033[0m\r\n", capitalize(err.Error()))
+	}
+}
+
+// checklist shows a checklist of choices that can be selected with arrow keys and Space, and returns a JSON array.
+func (p *ttyPrompter) checklist(ctx context.Context, prompt *Prompt, choices []string, defaultValues []string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if prompt.Help != "" {
+		fmt.Fprintf(p.w, "\033[90m%s\033[0m\n", prompt.Help)
+	}
+
+	state, err := term.MakeRaw(int(p.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(p.in.Fd()), state)
+
+	selected := make([]bool, len(choices))
+	for i, choice := range choices {
+		selected[i] = slices.Contains(defaultValues, choice)
+	}
+
+	for {
+		if selected, err = selectChoices(ctx, p.keys, p.w, prompt.message(), choices, selected); err != nil {
+			return "", err
+		}
+
+		var values []string
+		for i, choice := range choices {
+			if selected[i] {
+				values = append(values, choice)
+			}
+		}
+
+		answer, err := formatList(values)
+		if err != nil {
+			return "", err
+		}
+		if err = prompt.validate(answer); err == nil {
+			return answer, nil
+		}
+		fmt.Fprintf(p.w, "\033[31m%s. Please try again.\033[0m\r\n", capitalize(err.Error()))
+	}
+}
+
+// readSecret reads a line from the terminal without echoing it.
+func (p *ttyPrompter) readSecret(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	state, err := term.MakeRaw(int(p.in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(p.in.Fd()), state)
+
+	return readSecret(ctx, p.keys, p.w)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
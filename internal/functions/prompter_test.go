@@ -0,0 +1,110 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTYPrompter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		stdin      string
+		prompt     *Prompt
+		ask        func(context.Context, *ttyPrompter, *Prompt) (any, error)
+		want       any
+		wantStderr []string
+	}{
+		{
+			name:   "string",
+			stdin:  "Earth\n",
+			prompt: &Prompt{Name: "name", Message: "What is the name", Help: "The project name."},
+			ask: func(ctx context.Context, p *ttyPrompter, prompt *Prompt) (any, error) {
+				return p.String(ctx, prompt, "world")
+			},
+			want:       "Earth",
+			wantStderr: []string{"The project name.", "What is the name (name)? \033[90m[world]"},
+		},
+		{
+			name:   "string (validate)",
+			stdin:  "Earth\nearth\n",
+			prompt: &Prompt{Name: "name", Message: "name", Validate: lowercase},
+			ask: func(ctx context.Context, p *ttyPrompter, prompt *Prompt) (any, error) {
+				return p.String(ctx, prompt, "")
+			},
+			want:       "earth",
+			wantStderr: []string{"name? \033[90m[]", "Expected lowercase. Please try again."},
+		},
+		{
+			name:   "int",
+			stdin:  "world\n\n",
+			prompt: &Prompt{Name: "year", Message: "year"},
+			ask: func(ctx context.Context, p *ttyPrompter, prompt *Prompt) (any, error) {
+				return p.Int(ctx, prompt, 2022)
+			},
+			want:       2022,
+			wantStderr: []string{"Expected an integer. Please try again."},
+		},
+		{
+			name:   "bool",
+			stdin:  "maybe\nno\n",
+			prompt: &Prompt{Name: "release", Message: "release"},
+			ask: func(ctx context.Context, p *ttyPrompter, prompt *Prompt) (any, error) {
+				return p.Bool(ctx, prompt, true)
+			},
+			want:       false,
+			wantStderr: []string{"[Y/n]", "Expected yes (Y) or no (N). Please try again."},
+		},
+		{
+			name:   "secret",
+			stdin:  "\n",
+			prompt: &Prompt{Name: "token", Message: "token"},
+			ask: func(ctx context.Context, p *ttyPrompter, prompt *Prompt) (any, error) {
+				return p.Secret(ctx, prompt, "s3cr3t")
+			},
+			want:       "s3cr3t",
+			wantStderr: []string{"token? \033[90m[********]"},
+		},
+		{
+			name:   "choice",
+			stdin:  "gpl\n2\n",
+			prompt: &Prompt{Name: "license", Message: "license"},
+			ask: func(ctx context.Context, p *ttyPrompter, prompt *Prompt) (any, error) {
+				return p.Choice(ctx, prompt, []string{"mit", "apache-2.0"}, "mit")
+			},
+			want:       "apache-2.0",
+			wantStderr: []string{"[mit/apache-2.0]", "Expected one of mit, apache-2.0. Please try again."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			sut := newTTYPrompter(bytes.NewBufferString(tt.stdin), &stderr)
+
+			got, err := tt.ask(context.Background(), sut, tt.prompt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			for _, want := range tt.wantStderr {
+				assert.Contains(t, stderr.String(), want)
+			}
+		})
+	}
+}
+
+func lowercase(s string) error {
+	if s != "" && s == string(bytes.ToLower([]byte(s))) {
+		return nil
+	}
+	return errors.New("expected lowercase")
+}
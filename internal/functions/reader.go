@@ -0,0 +1,88 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package functions
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// lineReader reads lines in the background so that waiting for input can be canceled.
+type lineReader struct {
+	r       *bufio.Reader
+	lines   chan lineResult
+	pending bool
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{
+		r:     bufio.NewReader(r),
+		lines: make(chan lineResult, 1),
+	}
+}
+
+// ReadLine reads the next line including the line feed, or returns ctx.Err() if ctx is done first.
+// A canceled read is not lost: it is returned from the next call to ReadLine.
+func (l *lineReader) ReadLine(ctx context.Context) (string, error) {
+	if !l.pending {
+		l.pending = true
+		go func() {
+			line, err := l.r.ReadString('\n')
+			l.lines <- lineResult{line, err}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-l.lines:
+		l.pending = false
+		return result.line, result.err
+	}
+}
+
+// keyReader reads keys in the background so that waiting for input in raw mode can be canceled.
+type keyReader struct {
+	r       *bufio.Reader
+	keys    chan keyResult
+	pending bool
+}
+
+type keyResult struct {
+	key rune
+	err error
+}
+
+func newKeyReader(r io.Reader) *keyReader {
+	return &keyReader{
+		r:    bufio.NewReader(r),
+		keys: make(chan keyResult, 1),
+	}
+}
+
+// ReadKey reads the next key, or returns ctx.Err() if ctx is done first.
+// A canceled read is not lost: it is returned from the next call to ReadKey.
+func (k *keyReader) ReadKey(ctx context.Context) (rune, error) {
+	if !k.pending {
+		k.pending = true
+		go func() {
+			key, _, err := k.r.ReadRune()
+			k.keys <- keyResult{key, err}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case result := <-k.keys:
+		k.pending = false
+		return result.key, result.err
+	}
+}
@@ -4,9 +4,13 @@
 package functions
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"unicode/utf8"
 )
 
 type paramValue interface {
@@ -14,7 +18,22 @@ type paramValue interface {
 
 	Description() string
 	Display() string
-	Format(string) (string, bool)
+	Format(string) (string, error) // Returns the value formatted as a string, or an error describing what was expected.
+	Type() string
+}
+
+// expected returns an error describing what a paramValue expects.
+func expected(v paramValue) error {
+	return fmt.Errorf("expected %s", v.Description())
+}
+
+// DefaultType returns the name of the parameter type for a default value e.g., "string", "int", "bool", "choice", "list", or "secret".
+func DefaultType(v any) (string, error) {
+	param, err := fromDefaultValue(v)
+	if err != nil {
+		return "", err
+	}
+	return param.Type(), nil
 }
 
 func fromDefaultValue(v any) (paramValue, error) {
@@ -25,6 +44,15 @@ func fromDefaultValue(v any) (paramValue, error) {
 		return &intValue{v}, nil
 	case bool:
 		return &boolValue{v}, nil
+	case Choices:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no choices")
+		}
+		return &choiceValue{v, v[0]}, nil
+	case List:
+		return &listValue{v, v}, nil
+	case Secret:
+		return &secretValue{string(v)}, nil
 	default:
 		return nil, fmt.Errorf("unsupported type %v", v)
 	}
@@ -42,8 +70,12 @@ func (v stringValue) Display() string {
 	return v.String()
 }
 
-func (v stringValue) Format(s string) (string, bool) {
-	return s, true
+func (v stringValue) Format(s string) (string, error) {
+	return s, nil
+}
+
+func (v stringValue) Type() string {
+	return "string"
 }
 
 func (v stringValue) String() string {
@@ -62,12 +94,16 @@ func (v intValue) Display() string {
 	return v.String()
 }
 
-func (v intValue) Format(s string) (string, bool) {
+func (v intValue) Format(s string) (string, error) {
 	_, err := strconv.ParseInt(s, 10, 32)
 	if err != nil {
-		return "", false
+		return "", expected(v)
 	}
-	return s, true
+	return s, nil
+}
+
+func (v intValue) Type() string {
+	return "int"
 }
 
 func (v intValue) String() string {
@@ -89,18 +125,22 @@ func (v boolValue) Display() string {
 	return "y/N"
 }
 
-func (v boolValue) Format(s string) (string, bool) {
+func (v boolValue) Format(s string) (string, error) {
 	if s == "" {
-		return v.String(), true
+		return v.String(), nil
 	}
 	if strings.EqualFold(s, "y") || strings.EqualFold(s, "yes") || strings.EqualFold(s, "true") {
-		return "true", true
+		return "true", nil
 	}
 	if strings.EqualFold(s, "n") || strings.EqualFold(s, "no") || strings.EqualFold(s, "false") {
 		// text/template's `if` treats zero values as false.
-		return "", true
+		return "", nil
 	}
-	return "", false
+	return "", expected(v)
+}
+
+func (v boolValue) Type() string {
+	return "bool"
 }
 
 func (v boolValue) String() string {
@@ -110,3 +150,293 @@ func (v boolValue) String() string {
 	// text/template's `if` treats zero values as false.
 	return ""
 }
+
+// Choices are values of which one must be chosen. The first is the default.
+type Choices []string
+
+// Choice returns Choices for the choice template function.
+func Choice(values ...string) (Choices, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("choice requires at least one value")
+	}
+	return Choices(values), nil
+}
+
+type choiceValue struct {
+	choices      []string
+	defaultValue string
+}
+
+func (v choiceValue) Description() string {
+	return "one of " + strings.Join(v.choices, ", ")
+}
+
+func (v choiceValue) Display() string {
+	return strings.Join(v.choices, "/")
+}
+
+func (v choiceValue) Format(s string) (string, error) {
+	for _, choice := range v.choices {
+		if strings.EqualFold(s, choice) {
+			return choice, nil
+		}
+	}
+	return "", expected(v)
+}
+
+func (v choiceValue) Type() string {
+	return "choice"
+}
+
+func (v choiceValue) String() string {
+	return v.defaultValue
+}
+
+// List is a list of values of which any may be chosen. All are chosen by default.
+type List []string
+
+// NewList returns a List for the list template function.
+func NewList(values ...string) List {
+	return List(values)
+}
+
+type listValue struct {
+	choices       []string // Optional values of which any may be chosen; otherwise, any values are valid.
+	defaultValues []string
+}
+
+func (v listValue) Description() string {
+	if len(v.choices) == 0 {
+		return "a comma-separated list"
+	}
+	return "any of " + strings.Join(v.choices, ", ")
+}
+
+func (v listValue) Display() string {
+	return strings.Join(v.defaultValues, ", ")
+}
+
+// Format parses a JSON array or comma-separated list and returns a JSON array.
+func (v listValue) Format(s string) (string, error) {
+	var values []string
+	if s = strings.TrimSpace(s); strings.HasPrefix(s, "[") {
+		if err := json.Unmarshal([]byte(s), &values); err != nil {
+			return "", expected(v)
+		}
+	} else {
+		for _, value := range strings.Split(s, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	if len(v.choices) > 0 {
+	values:
+		for i, value := range values {
+			for _, choice := range v.choices {
+				if strings.EqualFold(value, choice) {
+					values[i] = choice
+					continue values
+				}
+			}
+			return "", expected(v)
+		}
+	}
+
+	return formatList(values)
+}
+
+func (v listValue) Type() string {
+	return "list"
+}
+
+func (v listValue) String() string {
+	s, _ := formatList(v.defaultValues)
+	return s
+}
+
+// formatList returns values as a JSON array.
+func formatList(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
+
+// parseList parses a JSON array returned from formatList.
+func parseList(s string) ([]string, error) {
+	values := []string{}
+	err := json.Unmarshal([]byte(s), &values)
+	return values, err
+}
+
+// Secret is a string that is not echoed when prompted, saved with answers, or shown in dry runs.
+type Secret string
+
+// NewSecret returns a Secret with an optional default for the secret template function.
+func NewSecret(value ...string) (Secret, error) {
+	switch len(value) {
+	case 0:
+		return "", nil
+	case 1:
+		return Secret(value[0]), nil
+	default:
+		return "", fmt.Errorf("secret accepts at most one value")
+	}
+}
+
+// Mask is displayed in place of secrets.
+const Mask = "********"
+
+type secretValue struct {
+	defaultValue string
+}
+
+func (v secretValue) Description() string {
+	return "a secret"
+}
+
+// Display masks the default, if any.
+func (v secretValue) Display() string {
+	if v.defaultValue == "" {
+		return ""
+	}
+	return Mask
+}
+
+func (v secretValue) Format(s string) (string, error) {
+	return s, nil
+}
+
+func (v secretValue) Type() string {
+	return "secret"
+}
+
+func (v secretValue) String() string {
+	return v.defaultValue
+}
+
+// isSecret returns whether param is a secret, even if it must match a pattern.
+func isSecret(param paramValue) bool {
+	return param.Type() == "secret"
+}
+
+// Condition is whether a parameter should be prompted; otherwise, its default is used.
+type Condition bool
+
+// When returns a Condition for the when template function that is true if value is not a zero value
+// e.g., a non-empty string or list, like text/template's `if`.
+func When(value any) Condition {
+	truth, _ := template.IsTrue(value)
+	return Condition(truth)
+}
+
+// patternValue requires a value to match a regular expression.
+type patternValue struct {
+	paramValue
+	re *regexp.Regexp
+}
+
+func (v patternValue) Description() string {
+	return fmt.Sprintf("%s matching %s", v.paramValue.Description(), v.re)
+}
+
+func (v patternValue) Format(s string) (string, error) {
+	if !v.re.MatchString(s) {
+		return "", expected(v)
+	}
+	return v.paramValue.Format(s)
+}
+
+func (v patternValue) unwrap() paramValue {
+	return v.paramValue
+}
+
+// lengthValue requires a value to have a minimum or maximum number of characters.
+type lengthValue struct {
+	paramValue
+	min, max *int
+}
+
+func (v lengthValue) Description() string {
+	return fmt.Sprintf("%s with %s characters", v.paramValue.Description(), describeRange(v.min, v.max))
+}
+
+func (v lengthValue) Format(s string) (string, error) {
+	s, err := v.paramValue.Format(s)
+	if err != nil {
+		return "", err
+	}
+	if !inRange(utf8.RuneCountInString(s), v.min, v.max) {
+		return "", expected(v)
+	}
+	return s, nil
+}
+
+func (v lengthValue) unwrap() paramValue {
+	return v.paramValue
+}
+
+// rangeValue requires an integer to have a minimum or maximum value.
+type rangeValue struct {
+	paramValue
+	min, max *int
+}
+
+func (v rangeValue) Description() string {
+	return fmt.Sprintf("%s of %s", v.paramValue.Description(), describeRange(v.min, v.max))
+}
+
+func (v rangeValue) Format(s string) (string, error) {
+	s, err := v.paramValue.Format(s)
+	if err != nil {
+		return "", err
+	}
+	if i, err := strconv.Atoi(s); err != nil || !inRange(i, v.min, v.max) {
+		return "", expected(v)
+	}
+	return s, nil
+}
+
+func (v rangeValue) unwrap() paramValue {
+	return v.paramValue
+}
+
+// validatorValue requires a value to pass a custom validator.
+type validatorValue struct {
+	paramValue
+	validate func(string) error
+}
+
+func (v validatorValue) Format(s string) (string, error) {
+	s, err := v.paramValue.Format(s)
+	if err != nil {
+		return "", err
+	}
+	if err = v.validate(s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+func (v validatorValue) unwrap() paramValue {
+	return v.paramValue
+}
+
+// describeRange describes an inclusive range e.g., "1 to 10", "at least 1", or "at most 10".
+func describeRange(min, max *int) string {
+	switch {
+	case min != nil && max != nil:
+		return fmt.Sprintf("%d to %d", *min, *max)
+	case min != nil:
+		return fmt.Sprintf("at least %d", *min)
+	default:
+		return fmt.Sprintf("at most %d", *max)
+	}
+}
+
+func inRange(i int, min, max *int) bool {
+	return (min == nil || i >= *min) && (max == nil || i <= *max)
+}
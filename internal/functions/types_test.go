@@ -4,6 +4,8 @@
 package functions
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,6 +46,26 @@ func TestFromDefaultValue(t *testing.T) {
 			value: false,
 			want:  "",
 		},
+		{
+			name:  "choices",
+			value: Choices{"mit", "apache-2.0"},
+			want:  "mit",
+		},
+		{
+			name:    "no choices",
+			value:   Choices{},
+			wantErr: true,
+		},
+		{
+			name:  "list",
+			value: List{"linux", "windows"},
+			want:  `["linux","windows"]`,
+		},
+		{
+			name:  "empty list",
+			value: List{},
+			want:  `[]`,
+		},
 		{
 			name:    "unsupported",
 			value:   time.Now,
@@ -68,16 +90,44 @@ func TestFromDefaultValue(t *testing.T) {
 	}
 }
 
+func TestDefaultType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   any
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: "string"},
+		{value: 1, want: "int"},
+		{value: true, want: "bool"},
+		{value: Choices{"mit"}, want: "choice"},
+		{value: List{"linux"}, want: "list"},
+		{value: Secret(""), want: "secret"},
+		{value: time.Now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := DefaultType(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
 func TestIntValue_Format(t *testing.T) {
 	t.Parallel()
 
 	v := intValue{1}
-	got, ok := v.Format("2")
-	assert.True(t, ok)
+	got, err := v.Format("2")
+	assert.NoError(t, err)
 	assert.Equal(t, "2", got)
 
-	_, ok = v.Format("invalid")
-	assert.False(t, ok)
+	_, err = v.Format("invalid")
+	assert.Error(t, err)
 }
 
 func TestIntValue_String(t *testing.T) {
@@ -154,8 +204,8 @@ func TestBoolValue_Format(t *testing.T) {
 	for _, tt := range tests {
 		v := boolValue{tt.defaultValue}
 		t.Run(tt.name, func(t *testing.T) {
-			got, ok := v.Format(tt.value)
-			if !assert.NotEqual(t, tt.isInvalid, ok) {
+			got, err := v.Format(tt.value)
+			if !assert.Equal(t, tt.isInvalid, err != nil) {
 				return
 			}
 			assert.Equal(t, tt.want, got)
@@ -172,3 +222,136 @@ func TestBoolValue_String(t *testing.T) {
 	v.defaultValue = false
 	assert.Equal(t, "", v.String())
 }
+
+func TestChoiceValue_Format(t *testing.T) {
+	t.Parallel()
+
+	v := choiceValue{[]string{"mit", "apache-2.0"}, "mit"}
+	assert.Equal(t, "one of mit, apache-2.0", v.Description())
+	assert.Equal(t, "mit/apache-2.0", v.Display())
+
+	got, err := v.Format("Apache-2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "apache-2.0", got)
+
+	_, err = v.Format("bsd")
+	assert.Error(t, err)
+
+	_, err = v.Format("")
+	assert.Error(t, err)
+}
+
+func TestChoice(t *testing.T) {
+	t.Parallel()
+
+	got, err := Choice("mit", "apache-2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, Choices{"mit", "apache-2.0"}, got)
+
+	_, err = Choice()
+	assert.Error(t, err)
+}
+
+func TestListValue_Format(t *testing.T) {
+	t.Parallel()
+
+	v := listValue{[]string{"linux", "windows", "darwin"}, []string{"linux"}}
+	assert.Equal(t, "any of linux, windows, darwin", v.Description())
+	assert.Equal(t, "linux", v.Display())
+
+	got, err := v.Format("Windows, linux")
+	assert.NoError(t, err)
+	assert.Equal(t, `["windows","linux"]`, got)
+
+	got, err = v.Format(`["darwin"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, `["darwin"]`, got)
+
+	got, err = v.Format("")
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, got)
+
+	_, err = v.Format("bsd")
+	assert.Error(t, err)
+
+	_, err = v.Format(`["linux"`)
+	assert.Error(t, err)
+
+	v = listValue{nil, nil}
+	assert.Equal(t, "a comma-separated list", v.Description())
+
+	got, err = v.Format("a,b, c")
+	assert.NoError(t, err)
+	assert.Equal(t, `["a","b","c"]`, got)
+}
+
+func TestLengthValue_Format(t *testing.T) {
+	t.Parallel()
+
+	min, max := 2, 4
+	v := lengthValue{&stringValue{}, &min, &max}
+	assert.Equal(t, "a string with 2 to 4 characters", v.Description())
+
+	got, err := v.Format("äbc")
+	assert.NoError(t, err)
+	assert.Equal(t, "äbc", got)
+
+	_, err = v.Format("a")
+	assert.EqualError(t, err, "expected a string with 2 to 4 characters")
+
+	_, err = v.Format("abcde")
+	assert.Error(t, err)
+
+	v.max = nil
+	assert.Equal(t, "a string with at least 2 characters", v.Description())
+
+	v.min, v.max = nil, &max
+	assert.Equal(t, "a string with at most 4 characters", v.Description())
+}
+
+func TestRangeValue_Format(t *testing.T) {
+	t.Parallel()
+
+	min, max := 1, 10
+	v := rangeValue{&intValue{1}, &min, &max}
+	assert.Equal(t, "an integer of 1 to 10", v.Description())
+
+	got, err := v.Format("10")
+	assert.NoError(t, err)
+	assert.Equal(t, "10", got)
+
+	_, err = v.Format("0")
+	assert.EqualError(t, err, "expected an integer of 1 to 10")
+
+	_, err = v.Format("ten")
+	assert.EqualError(t, err, "expected an integer")
+}
+
+func TestValidatorValue_Format(t *testing.T) {
+	t.Parallel()
+
+	v := validatorValue{&stringValue{}, func(s string) error {
+		if strings.ContainsAny(s, " ") {
+			return errors.New("expected no spaces")
+		}
+		return nil
+	}}
+	assert.Equal(t, "a string", v.Description())
+
+	got, err := v.Format("go-template")
+	assert.NoError(t, err)
+	assert.Equal(t, "go-template", got)
+
+	_, err = v.Format("go template")
+	assert.EqualError(t, err, "expected no spaces")
+}
+
+func TestWhen(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Condition(true), When("true"))
+	assert.Equal(t, Condition(false), When(""))
+	assert.Equal(t, Condition(true), When([]string{"linux"}))
+	assert.Equal(t, Condition(false), When([]string{}))
+	assert.Equal(t, Condition(false), When(nil))
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package gitconfig
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config is a parsed git config with names like "user.name" or "remote.origin.url".
+// Section and variable names are lowercase; subsection names are case-sensitive.
+type Config map[string]string
+
+// Parse parses the content of a git config file. If a variable is set more than once, the last value is used.
+// Include directives are ignored.
+func Parse(content []byte) (Config, error) {
+	config := make(Config)
+
+	var section string
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: expected ]", lineNumber)
+			}
+
+			var err error
+			if section, err = parseSection(line[1:end]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+
+			// A variable may follow the section header on the same line.
+			if line = strings.TrimSpace(line[end+1:]); line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+		}
+
+		if section == "" {
+			return nil, fmt.Errorf("line %d: expected section", lineNumber)
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: expected name = value", lineNumber)
+		}
+
+		if !ok {
+			// A variable without a value is true.
+			config[section+"."+name] = "true"
+			continue
+		}
+
+		// Values ending with an unquoted backslash continue on the next line.
+		value, continued, err := parseValue(value)
+		for continued && i+1 < len(lines) {
+			i++
+			var next string
+			if next, continued, err = parseValue(lines[i]); err == nil {
+				value += next
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		config[section+"."+name] = value
+	}
+
+	return config, nil
+}
+
+// parseSection returns the section name e.g., "user" or "remote.origin" for `remote "origin"`.
+func parseSection(s string) (string, error) {
+	name, subsection, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		// Deprecated [section.subsection] syntax is case-insensitive.
+		return strings.ToLower(name), nil
+	}
+
+	subsection = strings.TrimSpace(subsection)
+	if len(subsection) < 2 || subsection[0] != '"' || subsection[len(subsection)-1] != '"' {
+		return "", fmt.Errorf("expected quoted subsection")
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(subsection)-1; i++ {
+		if subsection[i] == '\\' && i+1 < len(subsection)-1 {
+			i++
+		}
+		sb.WriteByte(subsection[i])
+	}
+
+	return strings.ToLower(name) + "." + sb.String(), nil
+}
+
+// parseValue parses a value with optional quotes, escape sequences, and comments,
+// and returns whether the value continues on the next line.
+func parseValue(s string) (value string, continued bool, err error) {
+	var sb strings.Builder
+	var quoted bool
+	var spaces int
+
+	s = strings.TrimLeft(s, " \t")
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(s) {
+				return sb.String(), true, nil
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'b':
+				sb.WriteByte('\b')
+			case '"', '\\':
+				sb.WriteByte(s[i])
+			default:
+				return "", false, fmt.Errorf("invalid escape sequence \\%c", s[i])
+			}
+			spaces = 0
+			continue
+		case c == '"':
+			quoted = !quoted
+			spaces = 0
+			continue
+		case !quoted && (c == '#' || c == ';'):
+			return sb.String()[:sb.Len()-spaces], false, nil
+		case !quoted && (c == ' ' || c == '\t'):
+			spaces++
+		default:
+			spaces = 0
+		}
+		sb.WriteByte(c)
+	}
+
+	if quoted {
+		return "", false, fmt.Errorf("expected closing quote")
+	}
+
+	return sb.String()[:sb.Len()-spaces], false, nil
+}
+
+// GlobalPaths returns the paths of the user's git config files in the order git reads them.
+// Files that do not exist are included.
+func GlobalPaths() []string {
+	var paths []string
+
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	home, err := os.UserHomeDir()
+	if xdg == "" && err == nil {
+		xdg = filepath.Join(home, ".config")
+	}
+	if xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "git", "config"))
+	}
+
+	if err == nil {
+		paths = append(paths, filepath.Join(home, ".gitconfig"))
+	}
+
+	return paths
+}
+
+// Params returns parameters derived from config: "git.name" and "git.email" from the user,
+// and "github.owner" and "github.repo" from the origin remote URL if hosted on GitHub.
+func Params(config Config) map[string]string {
+	params := make(map[string]string)
+	if name := config["user.name"]; name != "" {
+		params["git.name"] = name
+	}
+	if email := config["user.email"]; email != "" {
+		params["git.email"] = email
+	}
+
+	if host, owner, repo, ok := ParseRemoteURL(config["remote.origin.url"]); ok && strings.Contains(strings.ToLower(host), "github") {
+		params["github.owner"] = owner
+		params["github.repo"] = repo
+	}
+
+	return params
+}
+
+// ParseRemoteURL parses the host, owner, and repository name from a remote URL
+// e.g., "https://github.com/heaths/go-template.git" or "git@github.com:heaths/go-template.git".
+func ParseRemoteURL(s string) (host, owner, repo string, ok bool) {
+	var path string
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return
+		}
+		host, path = u.Hostname(), u.Path
+	} else if i := strings.Index(s, ":"); i > 0 && !strings.Contains(s[:i], "/") {
+		// scp-like syntax e.g., "git@github.com:heaths/go-template.git".
+		host, path = s[:i], s[i+1:]
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+	} else {
+		return
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	owner, repo, found := strings.Cut(path, "/")
+	if !found || host == "" || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", "", false
+	}
+
+	return host, owner, repo, true
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package gitconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    Config
+		wantErr string
+	}{
+		{
+			name: "config",
+			content: heredoc.Doc(`
+				# comment
+				[user]
+					name = Heath Stewart ; comment
+					Email = "heaths@example.com"
+				[remote "Origin"]
+					url = git@github.com:heaths/go-template.git
+					fetch = +refs/heads/*:refs/remotes/origin/*
+				[core]
+					bare
+					editor = "code --wait # not a comment"
+				[Branch.Main] remote = origin
+			`),
+			want: Config{
+				"user.name":           "Heath Stewart",
+				"user.email":          "heaths@example.com",
+				"remote.Origin.url":   "git@github.com:heaths/go-template.git",
+				"remote.Origin.fetch": "+refs/heads/*:refs/remotes/origin/*",
+				"core.bare":           "true",
+				"core.editor":         "code --wait # not a comment",
+				"branch.main.remote":  "origin",
+			},
+		},
+		{
+			name:    "escapes",
+			content: "[alias]\n\tlg = log \\\n\t--oneline\n\tsay = \"a\\tb\\\\c\\\"\"\n",
+			want: Config{
+				"alias.lg":  "log --oneline",
+				"alias.say": "a\tb\\c\"",
+			},
+		},
+		{
+			name:    "last value",
+			content: "[user]\nname = a\n[user]\nname = b\n",
+			want:    Config{"user.name": "b"},
+		},
+		{
+			name:    "no section",
+			content: "name = a\n",
+			wantErr: "line 1: expected section",
+		},
+		{
+			name:    "unclosed section",
+			content: "[user\n",
+			wantErr: "line 1: expected ]",
+		},
+		{
+			name:    "unquoted subsection",
+			content: "[remote origin]\n",
+			wantErr: "line 1: expected quoted subsection",
+		},
+		{
+			name:    "unclosed quote",
+			content: "[user]\nname = \"a\n",
+			wantErr: "line 2: expected closing quote",
+		},
+		{
+			name:    "invalid escape",
+			content: "[user]\nname = \\a\n",
+			wantErr: `line 2: invalid escape sequence \a`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.content))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGlobalPaths(t *testing.T) {
+	// os.UserHomeDir reads USERPROFILE on Windows.
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("USERPROFILE", "/home/user")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	assert.Equal(t, []string{
+		filepath.Join("/home/user", ".config", "git", "config"),
+		filepath.Join("/home/user", ".gitconfig"),
+	}, GlobalPaths())
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	assert.Equal(t, []string{
+		filepath.Join("/xdg", "git", "config"),
+		filepath.Join("/home/user", ".gitconfig"),
+	}, GlobalPaths())
+}
+
+func TestParams(t *testing.T) {
+	t.Parallel()
+
+	got := Params(Config{
+		"user.name":         "Heath Stewart",
+		"user.email":        "heaths@example.com",
+		"remote.origin.url": "https://github.com/heaths/go-template.git",
+	})
+	assert.Equal(t, map[string]string{
+		"git.name":     "Heath Stewart",
+		"git.email":    "heaths@example.com",
+		"github.owner": "heaths",
+		"github.repo":  "go-template",
+	}, got)
+
+	got = Params(Config{
+		"user.name":         "Heath Stewart",
+		"remote.origin.url": "https://dev.azure.com/heaths/go-template",
+	})
+	assert.Equal(t, map[string]string{
+		"git.name": "Heath Stewart",
+	}, got)
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url    string
+		host   string
+		owner  string
+		repo   string
+		wantOK bool
+	}{
+		{url: "https://github.com/heaths/go-template.git", host: "github.com", owner: "heaths", repo: "go-template", wantOK: true},
+		{url: "https://user@github.com/heaths/go-template/", host: "github.com", owner: "heaths", repo: "go-template", wantOK: true},
+		{url: "ssh://git@github.com:22/heaths/go-template", host: "github.com", owner: "heaths", repo: "go-template", wantOK: true},
+		{url: "git@github.com:heaths/go-template.git", host: "github.com", owner: "heaths", repo: "go-template", wantOK: true},
+		{url: "github.example.com:heaths/go-template", host: "github.example.com", owner: "heaths", repo: "go-template", wantOK: true},
+		{url: "https://github.com/heaths"},
+		{url: "https://dev.azure.com/heaths/project/_git/repo"},
+		{url: "../go-template"},
+		{url: "/src/heaths/go-template"},
+		{url: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, owner, repo, ok := ParseRemoteURL(tt.url)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.owner, owner)
+			assert.Equal(t, tt.repo, repo)
+		})
+	}
+}
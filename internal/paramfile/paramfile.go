@@ -0,0 +1,217 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package paramfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of a parameters file.
+type Format int
+
+const (
+	FormatUnknown Format = iota // Detect the format from content.
+	FormatJSON
+	FormatYAML
+	FormatTOML
+	FormatEnv
+)
+
+// FormatOf returns the format for a file name based on its extension,
+// or FormatUnknown if the format should be detected from content.
+func FormatOf(name string) Format {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch {
+	case ext == ".json":
+		return FormatJSON
+	case ext == ".yaml" || ext == ".yml":
+		return FormatYAML
+	case ext == ".toml":
+		return FormatTOML
+	case ext == ".env" || strings.EqualFold(filepath.Base(name), ".env"):
+		return FormatEnv
+	default:
+		return FormatUnknown
+	}
+}
+
+// Parse parses parameters from content in the given format.
+// Nested tables or objects are flattened into dotted names e.g., "github.owner".
+// Arrays are returned as JSON arrays.
+func Parse(format Format, content []byte) (map[string]string, error) {
+	switch format {
+	case FormatJSON:
+		return parseJSON(content)
+	case FormatYAML:
+		return parseYAML(content)
+	case FormatTOML:
+		return parseTOML(content)
+	case FormatEnv:
+		return parseEnv(content)
+	}
+
+	// Try formats from most to least strict.
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSON(content)
+	}
+	if params, err := parseYAML(content); err == nil {
+		return params, nil
+	}
+	if params, err := parseTOML(content); err == nil {
+		return params, nil
+	}
+	if params, err := parseEnv(content); err == nil {
+		return params, nil
+	}
+
+	return nil, errors.New("unknown format; expected JSON, YAML, TOML, or .env")
+}
+
+func parseJSON(content []byte) (map[string]string, error) {
+	var values map[string]any
+	if err := json.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string)
+	if err := flatten(params, "", values); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func parseYAML(content []byte) (map[string]string, error) {
+	var values map[string]any
+	if err := yaml.Unmarshal(content, &values); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	params := make(map[string]string)
+	if err := flatten(params, "", values); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func parseTOML(content []byte) (map[string]string, error) {
+	var values map[string]any
+	if err := toml.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]string)
+	if err := flatten(params, "", values); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func flatten(params map[string]string, prefix string, values map[string]any) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		switch value := values[key].(type) {
+		case nil:
+			continue
+		case map[string]any:
+			if err := flatten(params, name, value); err != nil {
+				return err
+			}
+		default:
+			s, err := format(value)
+			if err != nil {
+				return fmt.Errorf("parameter %q: %w", name, err)
+			}
+			params[name] = s
+		}
+	}
+
+	return nil
+}
+
+func format(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			s, err := format(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		b, err := json.Marshal(items)
+		return string(b), err
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// parseEnv parses lines of name=value pairs. Lines may start with "export" and values may be quoted.
+// Blank lines and lines starting with "#" are ignored.
+func parseEnv(content []byte) (map[string]string, error) {
+	params := make(map[string]string)
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: expected name=value", i+1)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			var err error
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if j := strings.Index(value, " #"); j >= 0 {
+				value = strings.TrimSpace(value[:j])
+			}
+		}
+
+		params[name] = value
+	}
+
+	return params, nil
+}
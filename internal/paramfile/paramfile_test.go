@@ -0,0 +1,207 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package paramfile
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOf(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Format{
+		"params.json": FormatJSON,
+		"params.YAML": FormatYAML,
+		"params.yml":  FormatYAML,
+		"params.toml": FormatTOML,
+		"params.env":  FormatEnv,
+		".env":        FormatEnv,
+		"-":           FormatUnknown,
+		"params":      FormatUnknown,
+	}
+
+	for name, want := range tests {
+		assert.Equal(t, want, FormatOf(name), name)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	want := map[string]string{
+		"name":         "template",
+		"github.owner": "heaths",
+		"github.repo":  "template-golang",
+		"copyright":    "2022",
+		"release":      "true",
+		"platforms":    `["linux","windows"]`,
+	}
+
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			format: FormatJSON,
+			content: heredoc.Doc(`
+				{
+					"name": "template",
+					"github": {"owner": "heaths", "repo": "template-golang"},
+					"copyright": 2022,
+					"release": true,
+					"platforms": ["linux", "windows"],
+					"ignored": null
+				}
+			`),
+		},
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			content: heredoc.Doc(`
+				name: template
+				github:
+				  owner: heaths
+				  repo: template-golang
+				copyright: 2022
+				release: true
+				platforms: [linux, windows]
+			`),
+		},
+		{
+			name:   "toml",
+			format: FormatTOML,
+			content: heredoc.Doc(`
+				# Parameters
+				name = "template"
+				copyright = 2_022 # year
+				release = true
+				platforms = ["linux", 'windows']
+
+				[github]
+				owner = "heaths"
+				"repo" = 'template-golang'
+			`),
+		},
+		{
+			name:   "env",
+			format: FormatEnv,
+			content: heredoc.Doc(`
+				# Parameters
+				name=template
+				export github.owner="heaths"
+				github.repo='template-golang'
+				copyright=2022 # year
+				release=true
+				platforms=["linux","windows"]
+			`),
+		},
+		{
+			name: "detect json",
+			content: heredoc.Doc(`
+				{"name": "template"}
+			`),
+			want: map[string]string{"name": "template"},
+		},
+		{
+			name: "detect yaml",
+			content: heredoc.Doc(`
+				name: template
+			`),
+			want: map[string]string{"name": "template"},
+		},
+		{
+			name: "detect toml",
+			content: heredoc.Doc(`
+				[github]
+				owner = "heaths"
+			`),
+			want: map[string]string{"github.owner": "heaths"},
+		},
+		{
+			name: "detect env",
+			content: heredoc.Doc(`
+				NAME=template
+			`),
+			want: map[string]string{"NAME": "template"},
+		},
+		{
+			name:    "invalid toml",
+			format:  FormatTOML,
+			content: "name = template",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate toml",
+			format:  FormatTOML,
+			content: "name = \"a\"\nname = \"b\"",
+			wantErr: true,
+		},
+		{
+			name:   "toml integers",
+			format: FormatTOML,
+			content: heredoc.Doc(`
+				hex = 0x1F
+				octal = 0o10
+				binary = 0b101
+				negative = -1_000
+			`),
+			want: map[string]string{"hex": "31", "octal": "8", "binary": "5", "negative": "-1000"},
+		},
+		{
+			name:   "toml multi-line",
+			format: FormatTOML,
+			content: heredoc.Doc(`
+				description = """
+				A template."""
+				platforms = [
+					"linux",
+					"windows",
+				]
+				github = { owner = "heaths", repo = "template-golang" }
+			`),
+			want: map[string]string{
+				"description":  "A template.",
+				"platforms":    `["linux","windows"]`,
+				"github.owner": "heaths",
+				"github.repo":  "template-golang",
+			},
+		},
+		{
+			name:    "leading zero toml",
+			format:  FormatTOML,
+			content: "port = 010",
+			wantErr: true,
+		},
+		{
+			name:    "invalid env",
+			format:  FormatEnv,
+			content: "name template",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, []byte(tt.content))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.want == nil {
+				tt.want = want
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path"
+
+	"github.com/spf13/afero"
+	"golang.org/x/exp/maps"
+)
+
+// DefaultAnswersFile is the conventional name of the answers file in the destination root.
+const DefaultAnswersFile = ".template-answers.json"
+
+// loadAnswers reads previous answers from AnswersFile in the destination root, if any.
+func (p *Processor) loadAnswers(root string) (map[string]string, error) {
+	p.answersPath = ""
+	if p.AnswersFile == "" {
+		return nil, nil
+	}
+
+	p.answersPath = path.Join(root, p.AnswersFile)
+	content, err := afero.ReadFile(p.dstFS, p.answersPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, newTemplateError(p.answersPath, PhaseRead, err, nil)
+	}
+
+	p.logVerbose("loading answers %q", p.answersPath)
+	var answers map[string]string
+	if err := json.Unmarshal(content, &answers); err != nil {
+		return nil, newTemplateError(p.answersPath, PhaseParse, err, nil)
+	}
+
+	return answers, nil
+}
+
+// stageAnswers stages previous answers updated with resolved parameters to write to AnswersFile.
+// Nothing is staged if there are neither previous answers nor resolved parameters.
+func (p *Processor) stageAnswers(tx *transaction, previous, resolved map[string]string) error {
+	if p.answersPath == "" || len(previous) == 0 && len(resolved) == 0 {
+		return nil
+	}
+
+	answers := maps.Clone(previous)
+	if answers == nil {
+		answers = make(map[string]string, len(resolved))
+	}
+	maps.Copy(answers, resolved)
+
+	content, err := marshalAnswers(answers)
+	if err != nil {
+		return err
+	}
+
+	action := ActionRewrite
+	source, err := afero.ReadFile(p.dstFS, p.answersPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		action = ActionCreate
+	}
+
+	tx.write(p.answersPath, content)
+	p.Plan.add(p.answersPath, action, source, content)
+
+	return nil
+}
+
+func marshalAnswers(answers map[string]string) ([]byte, error) {
+	content, err := json.MarshalIndent(answers, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
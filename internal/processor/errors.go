@@ -0,0 +1,172 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Phase describes when an error occurred while processing a file.
+type Phase int
+
+const (
+	PhaseRead    Phase = iota // The file could not be read.
+	PhaseParse                // The template could not be parsed.
+	PhaseExecute              // The template could not be executed.
+	PhaseWrite                // The output could not be written.
+	PhaseDelete               // The file could not be deleted.
+	PhaseMove                 // The file could not be moved.
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseRead:
+		return "read"
+	case PhaseParse:
+		return "parse"
+	case PhaseExecute:
+		return "execute"
+	case PhaseWrite:
+		return "write"
+	case PhaseDelete:
+		return "delete"
+	case PhaseMove:
+		return "move"
+	default:
+		return "unknown"
+	}
+}
+
+// TemplateError is an error processing a file with the position in the template, if known.
+type TemplateError struct {
+	Path   string // The path relative to the root.
+	Line   int    // The 1-based line number, or 0 if unknown.
+	Column int    // The 1-based column in bytes, or 0 if unknown.
+	Phase  Phase  // When the error occurred.
+	Err    error  // The underlying error.
+
+	message string // The error message without template location.
+	source  string // The line of source at Line.
+}
+
+// text/template errors are formatted as "template: name:line[:col]: message".
+var templateErrorRegexp = regexp.MustCompile(`(?s)^template: [^:]*:(\d+)(?::(\d+))?: (.*)$`)
+
+func newTemplateError(path string, phase Phase, err error, source []byte) *TemplateError {
+	e := &TemplateError{
+		Path:    path,
+		Phase:   phase,
+		Err:     err,
+		message: err.Error(),
+	}
+
+	if phase != PhaseParse && phase != PhaseExecute {
+		return e
+	}
+
+	m := templateErrorRegexp.FindStringSubmatch(e.message)
+	if m == nil {
+		return e
+	}
+
+	e.Line, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		// text/template reports 0-based byte offsets.
+		col, _ := strconv.Atoi(m[2])
+		e.Column = col + 1
+	}
+	e.message = m[3]
+
+	lines := strings.Split(string(source), "\n")
+	if e.Line > 0 && e.Line <= len(lines) {
+		e.source = strings.TrimRight(lines[e.Line-1], "\r")
+	}
+
+	return e
+}
+
+func (e *TemplateError) Error() string {
+	var location string
+	switch {
+	case e.Column > 0:
+		location = fmt.Sprintf("%s:%d:%d", e.Path, e.Line, e.Column)
+	case e.Line > 0:
+		location = fmt.Sprintf("%s:%d", e.Path, e.Line)
+	default:
+		location = e.Path
+	}
+
+	return fmt.Sprintf("%s: failed to %s: %s", location, e.Phase, e.message)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// Excerpt returns the line of the template where the error occurred
+// and, if the column is known, a caret pointing to the column, e.g.:
+//
+//	3 | Project "{{param "name" | titlecase}}"
+//	  |            ^
+//
+// An empty string is returned if the line is not known.
+func (e *TemplateError) Excerpt() string {
+	if e.Line == 0 || e.source == "" {
+		return ""
+	}
+
+	gutter := strconv.Itoa(e.Line)
+	excerpt := fmt.Sprintf("%s | %s\n", gutter, e.source)
+	if e.Column > 0 && e.Column <= len(e.source)+1 {
+		// Keep tabs so the caret lines up with the source.
+		indent := strings.Map(func(r rune) rune {
+			if r == '\t' {
+				return r
+			}
+			return ' '
+		}, e.source[:e.Column-1])
+		excerpt += fmt.Sprintf("%s | %s^\n", strings.Repeat(" ", len(gutter)), indent)
+	}
+
+	return excerpt
+}
+
+// Errors is a list of errors that unwraps to each error.
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Is returns true if any error matches target. Before Go 1.20, errors.Is does not call Unwrap() []error.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error that matches target. Before Go 1.20, errors.As does not call Unwrap() []error.
+func (e Errors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
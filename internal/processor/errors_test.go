@@ -0,0 +1,102 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTemplateError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		source      string
+		phase       Phase
+		wantLine    int
+		wantColumn  int
+		wantError   string
+		wantExcerpt string
+	}{
+		{
+			name:      "parse",
+			source:    "# Title\n\n{{undefined \"name\"}}\n",
+			phase:     PhaseParse,
+			wantLine:  3,
+			wantError: `a.md:3: failed to parse: function "undefined" not defined`,
+			wantExcerpt: heredoc.Doc(`
+				3 | {{undefined "name"}}
+			`),
+		},
+		{
+			name:       "execute",
+			source:     "# Title\n\n\tProject {{fail \"name\"}}\n",
+			phase:      PhaseExecute,
+			wantLine:   3,
+			wantColumn: 12,
+			wantError:  `a.md:3:12: failed to execute: executing "a.md" at <fail "name">: error calling fail: failed`,
+			wantExcerpt: "3 | \tProject {{fail \"name\"}}\n" +
+				"  | \t          ^\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.New("a.md").Funcs(template.FuncMap{
+				"fail": func(string) (string, error) {
+					return "", errors.New("failed")
+				},
+			})
+
+			var err error
+			tmpl, err = tmpl.Parse(tt.source)
+			if err == nil {
+				err = tmpl.Execute(&strings.Builder{}, nil)
+			}
+			require.Error(t, err)
+
+			sut := newTemplateError("a.md", tt.phase, err, []byte(tt.source))
+			assert.Equal(t, tt.wantLine, sut.Line)
+			assert.Equal(t, tt.wantColumn, sut.Column)
+			assert.Equal(t, tt.wantError, sut.Error())
+			assert.Equal(t, tt.wantExcerpt, sut.Excerpt())
+			assert.ErrorIs(t, sut, err)
+		})
+	}
+}
+
+func TestNewTemplateError_noPosition(t *testing.T) {
+	t.Parallel()
+
+	err := errors.New("file does not exist")
+	sut := newTemplateError("a.md", PhaseDelete, err, nil)
+	assert.Equal(t, "a.md: failed to delete: file does not exist", sut.Error())
+	assert.Empty(t, sut.Excerpt())
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	a := errors.New("a")
+	b := errors.New("b")
+	sut := Errors{a, b}
+
+	assert.Equal(t, "a\nb", sut.Error())
+	assert.Equal(t, []error{a, b}, sut.Unwrap())
+	assert.True(t, sut.Is(b))
+	assert.False(t, sut.Is(errors.New("b")))
+
+	c := newTemplateError("c.md", PhaseParse, errors.New("c"), nil)
+	var templateErr *TemplateError
+	assert.True(t, Errors{a, c}.As(&templateErr))
+	assert.Same(t, c, templateErr)
+	assert.False(t, sut.As(&templateErr))
+}
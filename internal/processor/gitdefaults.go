@@ -0,0 +1,61 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/heaths/go-template/internal/gitconfig"
+	"github.com/spf13/afero"
+	"golang.org/x/exp/maps"
+)
+
+// loadGitDefaults returns defaults for parameters like "git.name" and "github.repo" read from the user's
+// git config files and then the git config in the destination root, if GitDefaults is set.
+// Files that cannot be read or parsed are ignored since defaults are only a convenience.
+func (p *Processor) loadGitDefaults(root string) map[string]string {
+	if !p.GitDefaults {
+		return nil
+	} else if p.gitDefaults != nil {
+		return p.gitDefaults
+	}
+
+	paths := p.gitConfigs
+	if paths == nil {
+		paths = gitconfig.GlobalPaths()
+	}
+
+	config := make(gitconfig.Config)
+	for _, name := range paths {
+		content, err := os.ReadFile(name)
+		p.mergeGitConfig(config, name, content, err)
+	}
+
+	name := path.Join(root, ".git", "config")
+	content, err := afero.ReadFile(p.dstFS, name)
+	p.mergeGitConfig(config, name, content, err)
+
+	return gitconfig.Params(config)
+}
+
+func (p *Processor) mergeGitConfig(config gitconfig.Config, name string, content []byte, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	} else if err != nil {
+		p.logVerbose("skipping git config %q: %v", name, err)
+		return
+	}
+
+	c, err := gitconfig.Parse(content)
+	if err != nil {
+		p.logVerbose("skipping git config %q: %v", name, err)
+		return
+	}
+
+	p.logVerbose("loading git config %q", name)
+	maps.Copy(config, c)
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/heaths/go-template/internal/functions"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestNames are the names of an optional manifest in the root directory in order of precedence.
+var manifestNames = []string{".template.yaml", ".template.yml", ".template.json"}
+
+// Manifest declares parameters used by templates.
+type Manifest struct {
+	Params []*functions.Definition `json:"params" yaml:"params"` // Parameters in the order users are prompted.
+
+	path string // The path to the manifest relative to the root.
+}
+
+// definitions returns parameter definitions by name.
+func (m *Manifest) definitions() map[string]*functions.Definition {
+	if m == nil {
+		return nil
+	}
+
+	defs := make(map[string]*functions.Definition, len(m.Params))
+	for _, def := range m.Params {
+		defs[def.Name] = def
+	}
+	return defs
+}
+
+// loadManifest loads the first manifest found in root, or returns nil if none was found.
+func (p *Processor) loadManifest(root string) (*Manifest, error) {
+	for _, name := range manifestNames {
+		manifestPath := path.Join(root, name)
+		content, err := afero.ReadFile(p.srcFS, manifestPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, newTemplateError(manifestPath, PhaseRead, err, nil)
+		}
+
+		p.logVerbose("loading manifest %q", manifestPath)
+		m, err := parseManifest(name, content)
+		if err != nil {
+			return nil, newTemplateError(manifestPath, PhaseParse, err, nil)
+		}
+
+		m.path = manifestPath
+		return m, nil
+	}
+
+	return nil, nil
+}
+
+func parseManifest(name string, content []byte) (*Manifest, error) {
+	m := new(Manifest)
+	if strings.HasSuffix(name, ".json") {
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(m); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		if err := dec.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	}
+
+	names := make(map[string]bool, len(m.Params))
+	for _, def := range m.Params {
+		if err := def.Validate(); err != nil {
+			return nil, err
+		}
+
+		if names[def.Name] {
+			return nil, fmt.Errorf("parameter %q declared more than once", def.Name)
+		}
+		names[def.Name] = true
+	}
+
+	return m, nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/heaths/go-template/internal/functions"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	t.Parallel()
+
+	minLength, maxLength, min := 1, 64, 1970
+	want := &Manifest{
+		Params: []*functions.Definition{
+			{Name: "name", Prompt: "What is the project name?", Pattern: "^[a-z-]+$", MinLength: &minLength, MaxLength: &maxLength},
+			{Name: "copyright", Default: 2022, Help: "The year of the copyright.", Min: &min},
+			{Name: "release", Type: "bool", Default: true},
+		},
+	}
+
+	// Normalize types and defaults like parseManifest.
+	for _, def := range want.Params {
+		require.NoError(t, def.Validate())
+	}
+	assert.Equal(t, "string", want.Params[0].Type)
+	assert.Equal(t, "int", want.Params[1].Type)
+
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  bool
+	}{
+		{
+			name: ".template.yaml",
+			manifest: heredoc.Doc(`
+				params:
+				- name: name
+				  prompt: What is the project name?
+				  pattern: ^[a-z-]+$
+				  minLength: 1
+				  maxLength: 64
+				- name: copyright
+				  default: 2022
+				  help: The year of the copyright.
+				  min: 1970
+				- name: release
+				  type: bool
+				  default: true
+			`),
+		},
+		{
+			name: ".template.json",
+			manifest: heredoc.Doc(`
+				{
+					"params": [
+						{"name": "name", "prompt": "What is the project name?", "pattern": "^[a-z-]+$", "minLength": 1, "maxLength": 64},
+						{"name": "copyright", "default": 2022, "help": "The year of the copyright.", "min": 1970},
+						{"name": "release", "type": "bool", "default": true}
+					]
+				}
+			`),
+		},
+		{
+			name:     "unknown field.yaml",
+			manifest: "params:\n- name: name\n  defualt: x\n",
+			wantErr:  true,
+		},
+		{
+			name:     "duplicate.yaml",
+			manifest: "params:\n- name: name\n- name: name\n",
+			wantErr:  true,
+		},
+		{
+			name:     "empty.yaml",
+			manifest: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseManifest(tt.name, []byte(tt.manifest))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.manifest == "" {
+				assert.Empty(t, got.Params)
+				return
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestProcessor_Execute_manifest(t *testing.T) {
+	t.Parallel()
+
+	const manifest = `
+params:
+- name: b
+  prompt: B?
+- name: a
+  default: 1
+- name: unused
+`
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".template.yaml", []byte(manifest), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "a" "ignored"}}`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte(`{{param "b"}}`), 0644))
+
+	var stderr bytes.Buffer
+	proc := Processor{
+		Stderr: &stderr,
+		Stdin:  bytes.NewBufferString("b\n2\n"),
+		IsTTY:  true,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "b.md"}, result.Templates)
+	assert.Equal(t, map[string]string{"a": "2", "b": "b"}, result.Params)
+	assert.NotContains(t, stderr.String(), "unused")
+
+	got, err := afero.ReadFile(srcFS, ".template.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, manifest, string(got))
+}
+
+func TestProcessor_Execute_manifestConditions(t *testing.T) {
+	t.Parallel()
+
+	const manifest = `
+params:
+- name: docker
+  default: false
+- name: docker.registry
+  default: docker.io
+  when: param "docker"
+- name: ci.enabled
+  default: true
+- name: ci
+  choices: [github, azure]
+  when: and (param "ci.enabled") (ne (param "docker.registry") "")
+`
+
+	tests := []struct {
+		name  string
+		stdin string
+		want  string
+	}{
+		{
+			name:  "skipped",
+			stdin: "n\nn\n",
+			want:  "docker.io ",
+		},
+		{
+			name:  "prompted",
+			stdin: "y\nghcr.io\ny\n2\n",
+			want:  "ghcr.io azure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcFS := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(srcFS, ".template.yaml", []byte(manifest), 0644))
+			require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "docker.registry"}} {{if param "ci.enabled"}}{{param "ci"}}{{end}}`), 0644))
+
+			proc := Processor{
+				Stderr: io.Discard,
+				Stdin:  bytes.NewBufferString(tt.stdin),
+				IsTTY:  true,
+
+				srcFS: srcFS,
+				dstFS: srcFS,
+			}
+			proc.Initialize()
+
+			_, err := proc.Execute(context.Background(), ".", make(map[string]string))
+			require.NoError(t, err)
+
+			got, err := afero.ReadFile(srcFS, "a.md")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestProcessor_Execute_manifestDefaults(t *testing.T) {
+	t.Parallel()
+
+	const manifest = `
+params:
+- name: github.owner
+- name: github.repo
+- name: module
+  default: github.com/{{github.owner}}/{{github.repo}}
+- name: loop
+  default: "{{loop}}"
+`
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".template.yaml", []byte(manifest), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "go.mod", []byte(`module {{param "module"}}`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "loop.md", []byte(`{{param "loop"}}`), 0644))
+
+	var stderr bytes.Buffer
+	proc := Processor{
+		Stderr: &stderr,
+		Stdin:  bytes.NewBufferString("heaths\ngo-template\n\n"),
+		IsTTY:  true,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.Len(t, result.Errors, 1)
+	assert.ErrorContains(t, err, `parameter "loop" default depends on itself`)
+	assert.Contains(t, stderr.String(), "module? \033[90m[github.com/heaths/go-template]")
+
+	got, err := afero.ReadFile(srcFS, "go.mod")
+	require.NoError(t, err)
+	assert.Equal(t, "module {{param \"module\"}}", string(got), "should not write changes because of errors")
+	assert.Equal(t, "github.com/heaths/go-template", result.Params["module"])
+}
+
+func TestProcessor_Execute_invalidManifest(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".template.json", []byte(`{"params": [{"name": "a", "type": "date"}]}`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "a" "a"}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	assert.EqualError(t, err, `.template.json: failed to parse: parameter "a" has unsupported type "date"`)
+	assert.Empty(t, result.Templates)
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/heaths/go-template/internal/functions"
+	"github.com/spf13/afero"
+	"golang.org/x/exp/slices"
+)
+
+// stageMoves stages files to move by moveFile or renameFile after all templates were executed,
+// so rendered templates and other files can be moved, swapped, or moved in place of deleted files.
+// Moves that would overwrite other files are recorded as errors.
+func (p *Processor) stageMoves(tx *transaction, moves []functions.Move) {
+	type move struct {
+		functions.Move
+		content  []byte
+		rendered bool
+	}
+
+	froms := make(map[string]bool)
+	for _, m := range moves {
+		froms[m.From] = true
+	}
+
+	// Read all files before staging any so files can be swapped.
+	targets := make(map[string]string)
+	var staged []move
+	for _, m := range moves {
+		if m.From == m.To {
+			continue
+		}
+
+		if err := p.checkMove(tx, m, froms, targets); err != nil {
+			p.addError(m.From, PhaseMove, err, nil)
+			continue
+		}
+
+		content, rendered := tx.staged(m.From)
+		if !rendered {
+			// Files that are not templates may exist only in the source.
+			var err error
+			if content, err = afero.ReadFile(p.dstFS, m.From); errors.Is(err, fs.ErrNotExist) {
+				content, err = afero.ReadFile(p.srcFS, m.From)
+			}
+			if err != nil {
+				p.addError(m.From, PhaseMove, err, nil)
+				continue
+			}
+		}
+
+		targets[m.To] = m.From
+		staged = append(staged, move{m, content, rendered})
+	}
+
+	for _, m := range staged {
+		if m.rendered {
+			tx.unstage(m.From)
+		}
+	}
+
+	for _, m := range staged {
+		p.logVerbose("moving %q to %q", m.From, m.To)
+
+		// Delete the original unless another file is moved in its place.
+		if _, ok := targets[m.From]; !ok {
+			if source, err := afero.ReadFile(p.dstFS, m.From); err == nil {
+				tx.delete(m.From)
+				tx.prune(path.Dir(m.From))
+				p.Plan.add(m.From, ActionDelete, source, nil)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				p.addError(m.From, PhaseMove, err, nil)
+				continue
+			}
+		}
+
+		action := ActionRewrite
+		if _, err := p.dstFS.Stat(m.To); errors.Is(err, fs.ErrNotExist) {
+			action = ActionCreate
+		}
+
+		tx.undelete(m.To)
+		tx.write(m.To, m.content)
+		if m.rendered {
+			p.Plan.move(m.From, m.To, action)
+			if i := slices.Index(p.result.Templates, m.From); i >= 0 {
+				p.result.Templates[i] = m.To
+			}
+		} else {
+			p.Plan.add(m.To, action, nil, m.content)
+		}
+	}
+}
+
+// checkMove returns an error if m would overwrite a file that is not also deleted or moved.
+func (p *Processor) checkMove(tx *transaction, m functions.Move, froms map[string]bool, targets map[string]string) error {
+	if other, ok := targets[m.To]; ok {
+		return fmt.Errorf("path %q conflicts with %q moved to the same path", m.To, other)
+	} else if tx.deleted(m.From) {
+		return errors.New("file is deleted")
+	} else if froms[m.To] || tx.deleted(m.To) {
+		return nil
+	}
+
+	if _, ok := tx.staged(m.To); ok {
+		return fmt.Errorf("path %q conflicts with a rendered template", m.To)
+	} else if _, err := p.dstFS.Stat(m.To); err == nil {
+		return fmt.Errorf("path %q already exists", m.To)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Execute_moveFile(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# go-template\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "README.template.md", []byte(`{{deleteFile "README.md"}}{{renameFile "README.md"}}# {{param "name"}}`+"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "licenses/MIT.txt", []byte("MIT\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "licenses/Apache-2.0.txt", []byte("Apache-2.0\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, ".github/license.yml", []byte(heredoc.Doc(`
+		{{- if eq (param "license") "mit" -}}
+		{{moveFile "licenses/MIT.txt" "LICENSE.txt"}}{{deleteFile "licenses/Apache-2.0.txt"}}
+		{{- else -}}
+		{{moveFile "licenses/Apache-2.0.txt" "LICENSE.txt"}}{{deleteFile "licenses/MIT.txt"}}
+		{{- end -}}
+		{{deleteFile}}
+	`)), 0644))
+
+	var plan Plan
+	proc := Processor{
+		Stderr: io.Discard,
+		Plan:   &plan,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"name": "template", "license": "mit"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"README.md", ".github/license.yml"}, result.Templates)
+	assert.ElementsMatch(t, []string{".github/license.yml", "licenses/Apache-2.0.txt", "README.template.md", "licenses/MIT.txt"}, result.Deleted)
+
+	got, err := afero.ReadFile(fs, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# template\n", string(got))
+
+	got, err = afero.ReadFile(fs, "LICENSE.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "MIT\n", string(got))
+
+	for _, name := range []string{"README.template.md", "licenses"} {
+		exists, err := afero.Exists(fs, name)
+		require.NoError(t, err)
+		assert.False(t, exists, name)
+	}
+
+	var changes []string
+	for _, change := range plan.Changes {
+		changes = append(changes, change.Action.String()+" "+change.Path)
+	}
+	assert.Contains(t, changes, "rewrite README.md")
+	assert.Contains(t, changes, "create LICENSE.txt")
+	assert.NotContains(t, changes, "create README.template.md")
+}
+
+func TestProcessor_Execute_moveFileRoot(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte("# outside\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "tpl/README.md", []byte("# go-template\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "tpl/README.template.md", []byte(`{{deleteFile "README.md"}}{{renameFile "README.md"}}# {{param "name"}}`+"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "tpl/docs/a.md", []byte(`{{moveFile "b.md"}}{{param "name"}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	// Paths passed to deleteFile, moveFile, and renameFile are relative to the root.
+	result, err := proc.Execute(context.Background(), "tpl", map[string]string{"name": "template"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"tpl/README.md", "tpl/b.md"}, result.Templates)
+	assert.ElementsMatch(t, []string{"tpl/README.template.md", "tpl/docs/a.md"}, result.Deleted)
+
+	for name, want := range map[string]string{
+		"README.md":     "# outside\n",
+		"tpl/README.md": "# template\n",
+		"tpl/b.md":      "template",
+	} {
+		got, err := afero.ReadFile(fs, name)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got), name)
+	}
+}
+
+func TestProcessor_Execute_moveFileSwap(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{renameFile "b.md"}}{{param "a"}}`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte(`{{moveFile "a.md"}}{{param "b"}}`), 0644))
+	dstFS := afero.NewMemMapFs()
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		srcFS: srcFS,
+		dstFS: dstFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"a": "A", "b": "B"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Deleted)
+
+	got, err := afero.ReadFile(dstFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "B", string(got))
+
+	got, err = afero.ReadFile(dstFS, "b.md")
+	require.NoError(t, err)
+	assert.Equal(t, "A", string(got))
+}
+
+func TestProcessor_Execute_moveFileConflict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "rendered",
+			files: map[string]string{
+				"a.md": `{{renameFile "b.md"}}`,
+				"b.md": `{{param "b"}}`,
+			},
+			wantErr: `a.md: failed to move: path "b.md" conflicts with a rendered template`,
+		},
+		{
+			name: "exists",
+			files: map[string]string{
+				"a.md": `{{renameFile "b.md"}}`,
+				"b.md": `b`,
+			},
+			wantErr: `a.md: failed to move: path "b.md" already exists`,
+		},
+		{
+			name: "same path",
+			files: map[string]string{
+				"a.md": `{{renameFile "c.md"}}`,
+				"b.md": `{{renameFile "c.md"}}`,
+			},
+			wantErr: `b.md: failed to move: path "c.md" conflicts with "a.md" moved to the same path`,
+		},
+		{
+			name: "deleted",
+			files: map[string]string{
+				"a.md": `{{deleteFile}}{{renameFile "b.md"}}`,
+			},
+			wantErr: `a.md: failed to move: file is deleted`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for name, content := range tt.files {
+				require.NoError(t, afero.WriteFile(fs, name, []byte(content), 0644))
+			}
+
+			proc := Processor{
+				Stderr: io.Discard,
+
+				srcFS: fs,
+				dstFS: fs,
+			}
+			proc.Initialize()
+
+			_, err := proc.Execute(context.Background(), ".", map[string]string{"b": "b"})
+			assert.EqualError(t, err, tt.wantErr)
+
+			// Nothing is written when moves conflict.
+			for name, content := range tt.files {
+				got, err := afero.ReadFile(fs, name)
+				require.NoError(t, err)
+				assert.Equal(t, content, string(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"io"
+	"os"
+
+	"github.com/heaths/go-template/internal/paramfile"
+)
+
+// loadParamsFiles reads ParamsFiles in order and adds their values to params.
+// Values in later files override those in earlier files, but values already in params take precedence.
+func (p *Processor) loadParamsFiles(params map[string]string) error {
+	values := make(map[string]string)
+	for _, name := range p.ParamsFiles {
+		var content []byte
+		var err error
+		if name == "-" {
+			content, err = io.ReadAll(p.Stdin)
+		} else {
+			content, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return newTemplateError(name, PhaseRead, err, nil)
+		}
+
+		p.logVerbose("loading parameters from %q", name)
+		fileValues, err := paramfile.Parse(paramfile.FormatOf(name), content)
+		if err != nil {
+			return newTemplateError(name, PhaseParse, err, nil)
+		}
+
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+
+	for key, value := range values {
+		if _, ok := params[key]; !ok {
+			params[key] = value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,398 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/heaths/go-template/internal/functions"
+)
+
+// Param describes a parameter used by templates.
+type Param struct {
+	Name    string     `json:"name"`
+	Type    string     `json:"type,omitempty"`    // The type of the default value e.g., "string", "int", "bool", "choice", or "list".
+	Default any        `json:"default,omitempty"` // The default value, if any.
+	Choices []string   `json:"choices,omitempty"` // The values of which one must be chosen for a "choice", or any for a "list".
+	Prompt  string     `json:"prompt,omitempty"`  // The prompt, if any.
+	When    string     `json:"when,omitempty"`    // The condition declared in a manifest under which the parameter is prompted, if any.
+	Help    string     `json:"help,omitempty"`    // Help declared in a manifest, if any.
+	Uses    []ParamUse `json:"uses"`              // Where the parameter is used.
+}
+
+// ParamUse is where a parameter is used in a template.
+type ParamUse struct {
+	Path   string `json:"path"`   // The path relative to the root.
+	Line   int    `json:"line"`   // The 1-based line number, or 0 if used in the path.
+	Column int    `json:"column"` // The 1-based column in bytes, or 0 if used in the path.
+}
+
+// setDefault sets the type and default of param from a value passed to the param function, if not already set.
+func (param *Param) setDefault(value any) {
+	if param.Type != "" || value == nil {
+		return
+	}
+
+	if typ, err := functions.DefaultType(value); err == nil {
+		param.Type = typ
+		param.Default = value
+		if choices, ok := value.(functions.Choices); ok {
+			param.Default, param.Choices = choices[0], choices
+		} else if list, ok := value.(functions.List); ok {
+			param.Choices = list
+		}
+	}
+}
+
+// ListParams parses all templates under root and returns the parameters declared in a manifest
+// followed by parameters used in the order they first appear, including in templated paths.
+// No templates are executed and no users are prompted.
+// Default values and prompts that cannot be evaluated on their own e.g., that use variables,
+// are not returned.
+func (p *Processor) ListParams(root string) ([]*Param, error) {
+	p.result = new(Result)
+
+	var err error
+	if p.manifest, err = p.loadManifest(root); err != nil {
+		return nil, err
+	}
+
+	noop := func(...string) string {
+		return ""
+	}
+	funcs := p.funcs(
+		func(string, ...any) (string, error) {
+			return "", errors.New("parameters cannot be evaluated")
+		},
+		noop, noop, noop, noop,
+	)
+
+	templates, err := p.parse(context.Background(), root, funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	var params []*Param
+	byName := make(map[string]*Param)
+	if p.manifest != nil {
+		for _, def := range p.manifest.Params {
+			param := &Param{
+				Name:    def.Name,
+				Type:    def.Type,
+				Default: def.Default,
+				Choices: def.Choices,
+				Prompt:  def.Prompt,
+				When:    def.When,
+				Help:    def.Help,
+			}
+			byName[def.Name] = param
+			params = append(params, param)
+		}
+	}
+
+	for _, tmpl := range templates {
+		for _, call := range findAllParams(tmpl.t) {
+			if len(call.Args) < 2 {
+				continue
+			}
+
+			name, ok := evaluateArg(call.Args[1], funcs).(string)
+			if !ok {
+				continue
+			}
+
+			param, ok := byName[name]
+			if !ok {
+				param = &Param{Name: name}
+				byName[name] = param
+				params = append(params, param)
+			}
+
+			if len(call.Args) > 2 && param.Type == "" {
+				param.setDefault(evaluateArg(call.Args[2], funcs))
+			}
+
+			if len(call.Args) > 3 && param.Prompt == "" {
+				if prompt, ok := evaluateArg(call.Args[3], funcs).(string); ok {
+					param.Prompt = prompt
+				}
+			}
+
+			line, column := position(tmpl.source, int(call.Position()))
+			param.Uses = append(param.Uses, ParamUse{
+				Path:   tmpl.path,
+				Line:   line,
+				Column: column,
+			})
+		}
+	}
+
+	// Record parameters used in paths by rendering them with a param function that records each call.
+	// Like when executed, placeholders like __name__ are parameters only if declared or used by a template.
+	var current string
+	pathFuncs := make(template.FuncMap, len(funcs))
+	for name, fn := range funcs {
+		pathFuncs[name] = fn
+	}
+	pathFuncs["param"] = func(name string, args ...any) (string, error) {
+		param, ok := byName[name]
+		if !ok {
+			param = &Param{Name: name}
+			byName[name] = param
+			params = append(params, param)
+		}
+
+		if len(args) > 0 {
+			param.setDefault(args[0])
+		}
+		if len(args) > 1 && param.Prompt == "" {
+			if prompt, ok := args[1].(string); ok {
+				param.Prompt = prompt
+			}
+		}
+
+		param.Uses = append(param.Uses, ParamUse{Path: current})
+		return "", nil
+	}
+
+	known := make(map[string]bool, len(byName))
+	for name := range byName {
+		known[name] = true
+	}
+	renderPath := p.pathRenderer(root, pathFuncs, func(name string) bool {
+		return known[name]
+	})
+
+	for _, tmpl := range templates {
+		if p.isTemplatePath(tmpl.path) {
+			// Errors are reported when executed.
+			current = tmpl.path
+			_, _ = renderPath(current)
+		}
+	}
+
+	if len(p.result.Errors) > 0 {
+		return params, Errors(p.result.Errors)
+	}
+
+	return params, nil
+}
+
+// evaluator returns a function that evaluates a template pipeline like the condition of an if action.
+func evaluator(funcs template.FuncMap) func(string) (bool, error) {
+	return func(pipeline string) (bool, error) {
+		t, err := template.New("when").Funcs(funcs).Parse("{{if " + pipeline + "}}true{{end}}")
+		if err != nil {
+			return false, err
+		}
+
+		var buf bytes.Buffer
+		if err = t.Execute(&buf, nil); err != nil {
+			return false, err
+		}
+		return buf.String() == "true", nil
+	}
+}
+
+// renderer returns a function that renders a default as a template in which a parameter name
+// like {{github.owner}} is shorthand for {{param "github.owner"}}.
+func (p *Processor) renderer(funcs template.FuncMap) func(string) (string, error) {
+	left, right := p.delims()
+	shorthand := regexp.MustCompile(regexp.QuoteMeta(left) + `\s*([A-Za-z_][\w.-]*)\s*` + regexp.QuoteMeta(right))
+
+	return func(s string) (string, error) {
+		if !strings.Contains(s, left) {
+			return s, nil
+		}
+
+		s = shorthand.ReplaceAllStringFunc(s, func(action string) string {
+			name := shorthand.FindStringSubmatch(action)[1]
+			if _, ok := funcs[name]; ok {
+				return action
+			}
+			return left + "param " + strconv.Quote(name) + right
+		})
+
+		t, err := template.New("default").Delims(left, right).Funcs(funcs).Parse(s)
+		if err != nil {
+			return "", err
+		}
+
+		var buf bytes.Buffer
+		if err = t.Execute(&buf, nil); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
+// collectParams resolves parameters in the order they are used by templates
+// so that users are prompted before any template is executed.
+// Parameters that cannot be resolved on their own e.g., that use variables,
+// or are within the body of an if, range, or with action are resolved when executed.
+// Parameters declared in a manifest are resolved first in the order they were declared.
+func (p *Processor) collectParams(ctx context.Context, templates []*parsedTemplate, funcs template.FuncMap) error {
+	resolve := func(call string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		t, err := template.New("param").Funcs(funcs).Parse("{{" + call + "}}")
+		if err != nil {
+			return nil
+		}
+
+		// Any other errors will be reported when the template is executed.
+		if err = t.Execute(io.Discard, nil); err != nil {
+			return canceled(ctx, err)
+		}
+		return nil
+	}
+
+	var calls []*parse.CommandNode
+	used := make(map[string]bool)
+	for _, tmpl := range templates {
+		for _, call := range findParams(tmpl.t.Root, false) {
+			calls = append(calls, call)
+			if len(call.Args) < 2 {
+				continue
+			}
+			if name, ok := call.Args[1].(*parse.StringNode); ok {
+				used[name.Text] = true
+			}
+		}
+	}
+
+	if p.manifest != nil {
+		for _, def := range p.manifest.Params {
+			if !used[def.Name] {
+				continue
+			}
+
+			if err := resolve(fmt.Sprintf("param %q", def.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, call := range calls {
+		if err := resolve(call.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findAllParams returns all calls to param in t and any templates it defines.
+func findAllParams(t *template.Template) []*parse.CommandNode {
+	defined := t.Templates()
+	sort.Slice(defined, func(i, j int) bool {
+		return defined[i].Name() < defined[j].Name()
+	})
+
+	calls := findParams(t.Root, true)
+	for _, d := range defined {
+		if d.Name() != t.Name() && d.Tree != nil {
+			calls = append(calls, findParams(d.Root, true)...)
+		}
+	}
+
+	return calls
+}
+
+// evaluateArg returns the value of a function argument, or nil if it cannot be evaluated on its own.
+func evaluateArg(node parse.Node, funcs template.FuncMap) any {
+	var value any
+	t, err := template.New("arg").Funcs(funcs).Funcs(template.FuncMap{
+		"capture": func(v any) string {
+			value = v
+			return ""
+		},
+	}).Parse("{{capture (" + node.String() + ")}}")
+	if err != nil {
+		return nil
+	}
+
+	if err = t.Execute(io.Discard, nil); err != nil {
+		return nil
+	}
+
+	return value
+}
+
+// position returns the 1-based line and column of the byte offset pos in source.
+func position(source []byte, pos int) (line, column int) {
+	text := string(source[:pos])
+	line = 1 + strings.Count(text, "\n")
+	column = pos - strings.LastIndex(text, "\n")
+	return
+}
+
+// findParams returns all calls to param under node in the order they appear.
+// Unless all is true, calls within the body of an if, range, or with action
+// are not returned since they may not be executed.
+func findParams(node parse.Node, all bool) []*parse.CommandNode {
+	var calls []*parse.CommandNode
+	walkParams(node, all, func(n *parse.CommandNode) {
+		calls = append(calls, n)
+	})
+	return calls
+}
+
+func walkParams(node parse.Node, all bool, fn func(*parse.CommandNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkParams(child, all, fn)
+		}
+	case *parse.ActionNode:
+		walkParams(n.Pipe, all, fn)
+	case *parse.TemplateNode:
+		walkParams(n.Pipe, all, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkParams(cmd, all, fn)
+		}
+	case *parse.CommandNode:
+		if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "param" {
+			fn(n)
+		}
+		for _, arg := range n.Args {
+			walkParams(arg, all, fn)
+		}
+	case *parse.ChainNode:
+		walkParams(n.Node, all, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, all, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, all, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, all, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, all bool, fn func(*parse.CommandNode)) {
+	walkParams(n.Pipe, all, fn)
+	if all {
+		walkParams(n.List, all, fn)
+		walkParams(n.ElseList, all, fn)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"context"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/heaths/go-template/internal/functions"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		all      bool
+		want     []string
+	}{
+		{
+			name:     "actions",
+			template: `{{param "a" "" "A?" | titlecase}} {{titlecase (param "b")}}`,
+			want:     []string{`param "a" "" "A?"`, `param "b"`},
+		},
+		{
+			name:     "branches",
+			template: `{{if param "a" true}}{{param "b"}}{{else}}{{param "c"}}{{end}}{{range $i := param "d"}}{{param "e"}}{{end}}`,
+			want:     []string{`param "a" true`, `param "d"`},
+		},
+		{
+			name:     "all branches",
+			template: `{{if param "a" true}}{{param "b"}}{{else}}{{param "c"}}{{end}}{{range $i := param "d"}}{{param "e"}}{{end}}`,
+			all:      true,
+			want:     []string{`param "a" true`, `param "b"`, `param "c"`, `param "d"`, `param "e"`},
+		},
+		{
+			name:     "nested",
+			template: `{{param "a" (param "b")}}`,
+			want:     []string{`param "a" (param "b")`, `param "b"`},
+		},
+		{
+			name:     "none",
+			template: `{{"param"}} {{titlecase "param"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New(tt.name).Funcs(template.FuncMap{
+				"param":     func(string, ...any) string { return "" },
+				"titlecase": func(string) string { return "" },
+			}).Parse(tt.template)
+			require.NoError(t, err)
+
+			var got []string
+			for _, call := range findParams(tmpl.Root, tt.all) {
+				got = append(got, call.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProcessor_collectParams(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	funcs := template.FuncMap{
+		"param": func(name string, args ...any) string {
+			got = append(got, name)
+			return ""
+		},
+	}
+
+	var templates []*parsedTemplate
+	for _, text := range []string{
+		`{{param "a"}}{{if param "b"}}{{param "c"}}{{end}}`,
+		`{{with $x := "x"}}{{param $x}}{{end}}{{$d := "d"}}{{param $d}}{{param "e" "e" "E?"}}`,
+	} {
+		tmpl, err := template.New("test").Funcs(funcs).Parse(text)
+		require.NoError(t, err)
+		templates = append(templates, &parsedTemplate{t: tmpl})
+	}
+
+	p := new(Processor)
+	err := p.collectParams(context.Background(), templates, funcs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "e"}, got)
+}
+
+func TestProcessor_ListParams(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, srcFS.Mkdir("build", 0755))
+	require.NoError(t, afero.WriteFile(srcFS, "build/a.md", []byte(`{{param "excluded"}}`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(content_a), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte("{{if param \"release\" true \"Release?\"}}\n  {{param \"copyright\" (date.Year)}}{{end}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "c.md", []byte("{{define \"x\"}}{{param \"name\" \"ignored\"}}{{end}}{{template \"x\"}}"), 0644))
+
+	p := Processor{
+		Exclusions: []string{"build"},
+
+		srcFS: srcFS,
+	}
+	p.Initialize()
+
+	params, err := p.ListParams(".")
+	require.NoError(t, err)
+
+	year := time.Now().UTC().Year()
+	want := []*Param{
+		{
+			Name:    "name",
+			Type:    "string",
+			Default: "",
+			Prompt:  "What is the project name?",
+			Uses: []ParamUse{
+				{Path: "a.md", Line: 1, Column: 5},
+				{Path: "a.md", Line: 3, Column: 12},
+				{Path: "c.md", Line: 1, Column: 17},
+			},
+		},
+		{
+			Name: "github.owner",
+			Uses: []ParamUse{{Path: "a.md", Line: 3, Column: 79}},
+		},
+		{
+			Name: "github.repo",
+			Uses: []ParamUse{{Path: "a.md", Line: 3, Column: 104}},
+		},
+		{
+			Name: "git.name",
+			Uses: []ParamUse{{Path: "a.md", Line: 5, Column: 33}},
+		},
+		{
+			Name:    "release",
+			Type:    "bool",
+			Default: true,
+			Prompt:  "Release?",
+			Uses:    []ParamUse{{Path: "b.md", Line: 1, Column: 6}},
+		},
+		{
+			Name:    "copyright",
+			Type:    "int",
+			Default: year,
+			Uses:    []ParamUse{{Path: "b.md", Line: 2, Column: 5}},
+		},
+	}
+	assert.Equal(t, want, params)
+}
+
+func TestProcessor_ListParams_paths(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "{{param \"a\" \"x\"}}.txt", nil, 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "cmd/__name__/main.go", []byte(`package main`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "src/__init__.py", nil, 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "README.md", []byte(`# {{param "name"}}`), 0644))
+
+	p := Processor{
+		srcFS: srcFS,
+	}
+	p.Initialize()
+
+	params, err := p.ListParams(".")
+	require.NoError(t, err)
+
+	want := []*Param{
+		{
+			Name: "name",
+			Uses: []ParamUse{
+				{Path: "README.md", Line: 1, Column: 5},
+				{Path: "cmd/__name__/main.go"},
+			},
+		},
+		{
+			Name:    "a",
+			Type:    "string",
+			Default: "x",
+			Uses:    []ParamUse{{Path: "{{param \"a\" \"x\"}}.txt"}},
+		},
+	}
+	assert.Equal(t, want, params)
+}
+
+func TestProcessor_ListParams_choice(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".template.yaml", []byte("params:\n- name: ci\n  choices: [github, azure]\n"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "license" (choice "mit" "apache-2.0")}}`), 0644))
+
+	p := Processor{
+		srcFS: srcFS,
+	}
+	p.Initialize()
+
+	params, err := p.ListParams(".")
+	require.NoError(t, err)
+
+	want := []*Param{
+		{Name: "ci", Type: "choice", Default: "github", Choices: []string{"github", "azure"}},
+		{Name: "license", Type: "choice", Default: "mit", Choices: []string{"mit", "apache-2.0"}, Uses: []ParamUse{{Path: "a.md", Line: 1, Column: 3}}},
+	}
+	assert.Equal(t, want, params)
+}
+
+func TestProcessor_ListParams_list(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".template.yaml", []byte("params:\n- name: os\n  type: list\n  choices: [linux, windows]\n  default: [linux]\n"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{range param "arch" (list "amd64" "arm64")}}{{.}}{{end}}`), 0644))
+
+	p := Processor{
+		srcFS: srcFS,
+	}
+	p.Initialize()
+
+	params, err := p.ListParams(".")
+	require.NoError(t, err)
+
+	want := []*Param{
+		{Name: "os", Type: "list", Default: functions.List{"linux"}, Choices: []string{"linux", "windows"}},
+		{Name: "arch", Type: "list", Default: functions.List{"amd64", "arm64"}, Choices: []string{"amd64", "arm64"}, Uses: []ParamUse{{Path: "a.md", Line: 1, Column: 9}}},
+	}
+	assert.Equal(t, want, params)
+}
+
+func TestProcessor_ListParams_manifest(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".template.yaml", []byte("params:\n- name: b\n  help: Help for b.\n  when: param \"c\"\n- name: a\n  default: 1\n"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "a" "ignored"}}{{param "c"}}`), 0644))
+
+	p := Processor{
+		srcFS: srcFS,
+	}
+	p.Initialize()
+
+	params, err := p.ListParams(".")
+	require.NoError(t, err)
+
+	want := []*Param{
+		{Name: "b", Type: "string", Default: "", When: `param "c"`, Help: "Help for b."},
+		{Name: "a", Type: "int", Default: 1, Uses: []ParamUse{{Path: "a.md", Line: 1, Column: 3}}},
+		{Name: "c", Uses: []ParamUse{{Path: "a.md", Line: 1, Column: 26}}},
+	}
+	assert.Equal(t, want, params)
+}
+
+func TestProcessor_renderer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		leftDelim  string
+		rightDelim string
+		value      string
+		want       string
+		wantErr    string
+	}{
+		{
+			name:  "literal",
+			value: "github.com",
+			want:  "github.com",
+		},
+		{
+			name:  "shorthand",
+			value: "github.com/{{github.owner}}/{{ github.repo }}",
+			want:  "github.com/heaths/go-template",
+		},
+		{
+			name:  "pipeline",
+			value: `{{param "github.repo" | uppercase}}`,
+			want:  "GO-TEMPLATE",
+		},
+		{
+			name:    "function",
+			value:   "{{uppercase}}",
+			wantErr: `template: default:1:2: executing "default" at <uppercase>: wrong number of args for uppercase: want 1 got 0`,
+		},
+		{
+			name:       "delims",
+			leftDelim:  "<%",
+			rightDelim: "%>",
+			value:      "{{github.owner}}/<%github.repo%>",
+			want:       "{{github.owner}}/go-template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Processor{
+				LeftDelim:  tt.leftDelim,
+				RightDelim: tt.rightDelim,
+			}
+			p.Initialize()
+
+			params := map[string]string{"github.owner": "heaths", "github.repo": "go-template"}
+			noop := func(...string) string {
+				return ""
+			}
+			funcs := p.funcs(func(name string) string {
+				return params[name]
+			}, noop, noop, noop, noop)
+
+			got, err := p.renderer(funcs)(tt.value)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// placeholder matches a parameter name in a path like "__name__" or "__github.repo__".
+var placeholder = regexp.MustCompile(`__([A-Za-z][\w.-]*?)__`)
+
+// delims returns the left and right delimiters, or the defaults "{{" and "}}".
+func (p *Processor) delims() (left, right string) {
+	if p.LeftDelim != "" && p.RightDelim != "" {
+		return p.LeftDelim, p.RightDelim
+	}
+	return "{{", "}}"
+}
+
+// isTemplatePath returns true if name may reference parameters.
+func (p *Processor) isTemplatePath(name string) bool {
+	left, _ := p.delims()
+	return strings.Contains(name, left) || placeholder.MatchString(name)
+}
+
+// pathRenderer returns a function that renders a path under root in which a parameter is referenced
+// by an action like {{param "name"}} or {{name}}, or by a placeholder like __name__ if known returns
+// true for the name, so other names like __init__ are left as is.
+func (p *Processor) pathRenderer(root string, funcs template.FuncMap, known func(string) bool) func(string) (string, error) {
+	left, right := p.delims()
+	render := p.renderer(funcs)
+	root = path.Clean(root)
+
+	return func(name string) (string, error) {
+		s := placeholder.ReplaceAllStringFunc(name, func(s string) string {
+			param := placeholder.FindStringSubmatch(s)[1]
+			if _, ok := funcs[param]; ok || !known(param) {
+				return s
+			}
+			return left + param + right
+		})
+
+		s, err := render(s)
+		if err != nil {
+			return "", err
+		} else if s == name {
+			return name, nil
+		}
+
+		for _, segment := range strings.Split(s, "/") {
+			if segment == "" {
+				return "", fmt.Errorf("path %q renders an empty segment: %q", name, s)
+			}
+		}
+
+		s = path.Clean(s)
+		if rel := strings.TrimPrefix(s, root+"/"); root != "." && rel == s || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+			return "", fmt.Errorf("path %q renders outside the root: %q", name, s)
+		}
+
+		return s, nil
+	}
+}
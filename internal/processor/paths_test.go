@@ -0,0 +1,184 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_pathRenderer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		root       string
+		leftDelim  string
+		rightDelim string
+		path       string
+		want       string
+		wantErr    string
+	}{
+		{
+			name: "literal",
+			path: "cmd/main.go",
+			want: "cmd/main.go",
+		},
+		{
+			name: "action",
+			path: `cmd/{{param "name"}}/main.go`,
+			want: "cmd/template/main.go",
+		},
+		{
+			name: "shorthand",
+			path: "{{github.owner}}/{{name}}.md",
+			want: "heaths/template.md",
+		},
+		{
+			name: "placeholder",
+			path: "pkg/__name__/__github.owner__.go",
+			want: "pkg/template/heaths.go",
+		},
+		{
+			name: "unknown placeholder",
+			path: "pkg/__init__.py",
+			want: "pkg/__init__.py",
+		},
+		{
+			name: "function placeholder",
+			path: "__date__.md",
+			want: "__date__.md",
+		},
+		{
+			name: "root",
+			root: "./src",
+			path: "./src/__name__/main.go",
+			want: "src/template/main.go",
+		},
+		{
+			name:       "delims",
+			leftDelim:  "<%",
+			rightDelim: "%>",
+			path:       "{{name}}/<%name%>/__name__.go",
+			want:       "{{name}}/template/template.go",
+		},
+		{
+			name:    "empty segment",
+			path:    `cmd/{{param "empty"}}/main.go`,
+			wantErr: `path "cmd/{{param \"empty\"}}/main.go" renders an empty segment: "cmd//main.go"`,
+		},
+		{
+			name:    "outside root",
+			path:    `{{param "parent"}}/main.go`,
+			wantErr: `path "{{param \"parent\"}}/main.go" renders outside the root: "../main.go"`,
+		},
+		{
+			name:    "outside subdirectory",
+			root:    "src",
+			path:    `src/{{param "parent"}}/main.go`,
+			wantErr: `path "src/{{param \"parent\"}}/main.go" renders outside the root: "main.go"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Processor{
+				LeftDelim:  tt.leftDelim,
+				RightDelim: tt.rightDelim,
+			}
+			p.Initialize()
+
+			params := map[string]string{"name": "template", "github.owner": "heaths", "empty": "", "parent": ".."}
+			noop := func(...string) string {
+				return ""
+			}
+			funcs := p.funcs(func(name string) string {
+				return params[name]
+			}, noop, noop, noop, noop)
+
+			root := tt.root
+			if root == "" {
+				root = "."
+			}
+
+			got, err := p.pathRenderer(root, funcs, func(name string) bool {
+				_, ok := params[name]
+				return ok
+			})(tt.path)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProcessor_Execute_paths(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, `cmd/{{param "name"}}/main.go`, []byte(`package main // {{param "name"}}`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "pkg/__name__/doc.go", []byte("package template"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "pkg/__name__/internal/__init__.py", []byte(""), 0644))
+	require.NoError(t, afero.WriteFile(fs, "README.md", []byte(`# {{param "name"}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"name": "template"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"README.md", "cmd/template/main.go", "pkg/template/doc.go", "pkg/template/internal/__init__.py"}, result.Templates)
+	assert.ElementsMatch(t, []string{`cmd/{{param "name"}}/main.go`, "pkg/__name__/doc.go", "pkg/__name__/internal/__init__.py"}, result.Deleted)
+
+	got, err := afero.ReadFile(fs, "cmd/template/main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main // template", string(got))
+
+	got, err = afero.ReadFile(fs, "pkg/template/doc.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package template", string(got))
+
+	// Templated directories are removed once empty.
+	for _, dir := range []string{`cmd/{{param "name"}}`, "pkg/__name__"} {
+		exists, err := afero.Exists(fs, dir)
+		require.NoError(t, err)
+		assert.False(t, exists, dir)
+	}
+}
+
+func TestProcessor_Execute_pathConflict(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "a.md", []byte(`{{param "name"}}`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "__name__.md", []byte("b"), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	_, err := proc.Execute(context.Background(), ".", map[string]string{"name": "a"})
+	assert.EqualError(t, err, `a.md: failed to write: path "a.md" conflicts with "__name__.md"`)
+
+	got, err := afero.ReadFile(fs, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, `{{param "name"}}`, string(got))
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"bytes"
+	"io"
+	"path"
+
+	"github.com/heaths/go-template/internal/diff"
+	"github.com/heaths/go-template/internal/functions"
+)
+
+// Action describes what was, or would be, done to a file.
+type Action int
+
+const (
+	ActionCreate  Action = iota // The file was created in the destination.
+	ActionRewrite               // The file already existed in the destination and was rewritten.
+	ActionSkip                  // The file was excluded or not a template.
+	ActionDelete                // The file was deleted by deleteFile, or moved.
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionCreate:
+		return "create"
+	case ActionRewrite:
+		return "rewrite"
+	case ActionSkip:
+		return "skip"
+	case ActionDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// Change describes a single change to a file.
+type Change struct {
+	Path    string // The path relative to the root.
+	Action  Action // The action taken on the file.
+	Source  []byte // The template source for ActionCreate and ActionRewrite, or the deleted content for ActionDelete.
+	Content []byte // The rendered content for ActionCreate and ActionRewrite.
+}
+
+// Plan records changes in the order they were made.
+type Plan struct {
+	Changes []Change
+}
+
+// WriteDiff writes a unified diff between the source and rendered content of each change to w.
+// Deleted files, and created files without a source e.g., emitted files, are compared to /dev/null.
+// Skipped files are not written.
+func (p *Plan) WriteDiff(w io.Writer) error {
+	for _, change := range p.Changes {
+		var err error
+		switch change.Action {
+		case ActionCreate, ActionRewrite:
+			from := path.Join("a", change.Path)
+			if change.Action == ActionCreate && change.Source == nil {
+				from = diff.DevNull
+			}
+			err = diff.Unified(w, from, path.Join("b", change.Path), change.Source, change.Content)
+		case ActionDelete:
+			err = diff.Unified(w, path.Join("a", change.Path), diff.DevNull, change.Source, nil)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mask replaces the values of secrets in the source and rendered content of each change.
+func (p *Plan) mask(secrets map[string]string) {
+	if p == nil {
+		return
+	}
+
+	// Sources may contain secrets too e.g., a project's current content when upgrading.
+	for i := range p.Changes {
+		c := &p.Changes[i]
+		for _, secret := range secrets {
+			if secret != "" {
+				c.Source = bytes.ReplaceAll(c.Source, []byte(secret), []byte(functions.Mask))
+				c.Content = bytes.ReplaceAll(c.Content, []byte(secret), []byte(functions.Mask))
+			}
+		}
+	}
+}
+
+// move changes the path and action of the change for a rendered template moved by moveFile or renameFile.
+func (p *Plan) move(from, to string, action Action) {
+	if p == nil {
+		return
+	}
+
+	for i := range p.Changes {
+		if c := &p.Changes[i]; c.Path == from && (c.Action == ActionCreate || c.Action == ActionRewrite) {
+			c.Path = to
+			c.Action = action
+			return
+		}
+	}
+}
+
+func (p *Plan) add(path string, action Action, source, content []byte) {
+	if p == nil {
+		return
+	}
+
+	p.Changes = append(p.Changes, Change{
+		Path:    path,
+		Action:  action,
+		Source:  source,
+		Content: content,
+	})
+}
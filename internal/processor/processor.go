@@ -5,11 +5,15 @@ package processor
 
 // cspell:ignore mattn isatty
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
 	"os"
+	"path"
 	"strings"
 	"text/template"
 	"text/template/parse"
@@ -17,6 +21,7 @@ import (
 	"github.com/heaths/go-template/internal/functions"
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/afero"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
@@ -27,6 +32,8 @@ type Processor struct {
 	Stdin  io.Reader // The reader from which user input is read.
 	IsTTY  bool      // Whether Stderr is a terminal.
 
+	Prompter functions.Prompter // Optional Prompter used instead of Stdin and Stderr.
+
 	LeftDelim  string   // Left delimiter e.g., "{{".
 	RightDelim string   // Right delimiter e.g., "}}".
 	Exclusions []string // Directories and files to exclude.
@@ -37,10 +44,30 @@ type Processor struct {
 	Log     *log.Logger // Optional logger for pertinent information.
 	Verbose bool        // Whether to log verbose information.
 
+	DryRun      bool      // Whether to render templates without writing to the destination.
+	KeepPartial bool      // Whether to write successfully rendered templates even if others failed.
+	Plan        *Plan     // Optional plan to record changes.
+	Diff        io.Writer // Optional writer for a unified diff of changes.
+
+	ParamsFiles []string // Files from which parameters are read in order; "-" reads from Stdin.
+	EnvPrefix   string   // Optional prefix of environment variables from which parameters are read e.g., "TEMPLATE".
+	AnswersFile string   // Optional file relative to the destination root in which resolved parameters are saved.
+
+	Validators  map[string]func(string) error // Optional validators for parameter values by name.
+	GitDefaults bool                          // Whether to read defaults for parameters like "git.name" and "github.repo" from git config.
+
+	NonInteractive bool // Whether to never prompt users.
+	AcceptDefaults bool // Whether to use default values instead of prompting users.
+
 	srcFS afero.Fs // The file system for reading templates.
 	dstFS afero.Fs // The file system for writing templates.
 
-	errors int // Number of errors logged (as warning logs).
+	manifest    *Manifest         // The optional manifest found in the root.
+	answersPath string            // The path to the answers file, if any.
+	secrets     map[string]string // Secret parameters masked in the Plan.
+	gitConfigs  []string          // Global git config files; the default is gitconfig.GlobalPaths().
+	gitDefaults map[string]string // Defaults read from git config, if already read e.g., from a project being upgraded.
+	result      *Result           // The result of the current Execute.
 }
 
 func (p *Processor) Initialize() {
@@ -67,42 +94,331 @@ func (p *Processor) Initialize() {
 	if p.dstFS == nil {
 		p.dstFS = p.srcFS
 	}
+
+	if p.Diff != nil && p.Plan == nil {
+		p.Plan = new(Plan)
+	}
 }
 
-func (p *Processor) Execute(root string, params map[string]string) error {
+// Execute processes all templates under root using params, and returns a Result describing
+// which files were processed. The Result is returned even if an error occurs.
+// If ctx is done, no more files are processed or deleted and ctx.Err() is returned.
+func (p *Processor) Execute(ctx context.Context, root string, params map[string]string) (*Result, error) {
+	p.result = new(Result)
+	p.secrets = make(map[string]string)
+	defer func() {
+		p.result.Params = maps.Clone(params)
+	}()
+
 	var current string
 	var deleteFiles bool
 	var filesToDelete []string
-	reset := func(path string) {
+	var filesToMove []functions.Move
+	var filesToEmit []functions.File
+	var executing *template.Template
+	reset := func(path string, t *template.Template) {
 		current = path
+		executing = t
 		deleteFiles = false
 		filesToDelete = nil
+		filesToMove = nil
+		filesToEmit = nil
 	}
 
-	// Keep track of files to delete until we're finished;
-	// otherwise, not all files to delete may yet exist in the destination FS.
+	// Keep track of files to delete or move until we're finished;
+	// otherwise, not all files to delete or move may yet exist in the destination FS.
 	allFilesToDelete := make([]string, 0)
+	var allFilesToMove []functions.Move
+	var allFilesToEmit []emittedFile
+
+	// Stage all changes so nothing is written unless every template succeeded.
+	tx := new(transaction)
+
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	if err := p.loadParamsFiles(params); err != nil {
+		p.result.Errors = append(p.result.Errors, err)
+		return p.result, Errors(p.result.Errors)
+	}
+
+	var err error
+	if p.manifest, err = p.loadManifest(root); err != nil {
+		p.result.Errors = append(p.result.Errors, err)
+		return p.result, Errors(p.result.Errors)
+	}
+
+	answers, err := p.loadAnswers(root)
+	if err != nil {
+		p.result.Errors = append(p.result.Errors, err)
+		return p.result, Errors(p.result.Errors)
+	}
+
+	resolver := &functions.Resolver{
+		Values:      params,
+		Definitions: p.manifest.definitions(),
+		Answers:     answers,
+		Resolved:    make(map[string]string),
+		Secrets:     p.secrets,
+		Validators:  p.Validators,
+		Defaults:    p.loadGitDefaults(root),
+		EnvPrefix:   p.EnvPrefix,
+
+		Stdin:  p.Stdin,
+		Stderr: p.Stderr,
+		IsTTY:  p.IsTTY,
+
+		Prompter:       p.Prompter,
+		NonInteractive: p.NonInteractive,
+		AcceptDefaults: p.AcceptDefaults,
+	}
+
+	funcs := p.funcs(
+		functions.ParamFunc(ctx, resolver),
+		functions.DeleteFunc(root, &current, &deleteFiles, &filesToDelete),
+		functions.MoveFunc(root, &current, &filesToMove),
+		functions.RenameFunc(root, &current, &filesToMove),
+		functions.EmitFunc(root, func(name string, data any) ([]byte, error) {
+			if executing == nil {
+				return nil, errors.New("files can be emitted only by templates")
+			}
+
+			var buf bytes.Buffer
+			err := executing.ExecuteTemplate(&buf, name, data)
+			return buf.Bytes(), err
+		}, &filesToEmit),
+	)
+
+	resolver.Evaluate = evaluator(funcs)
+	resolver.Render = p.renderer(funcs)
+
+	templates, err := p.parse(ctx, root, funcs)
+	if err == nil {
+		// Prompt for parameters before executing any templates.
+		err = p.collectParams(ctx, templates, funcs)
+	}
+
+	renderPath := p.pathRenderer(root, funcs, func(name string) bool {
+		_, ok := resolver.Values[name]
+		return ok || resolver.Definitions[name] != nil
+	})
+
+	// Keep track of which template rendered each path to detect conflicts,
+	// and of templates moved to a rendered path to delete the originals.
+	rendered := make(map[string]string)
+	var moved []string
+
+	for _, tmpl := range templates {
+		if err != nil {
+			break
+		}
+
+		path, pathErr := renderPath(tmpl.path)
+		if pathErr != nil {
+			if err = canceled(ctx, pathErr); err != nil {
+				break
+			}
+			p.addError(tmpl.path, PhaseExecute, pathErr, nil)
+			continue
+		} else if tmpl.raw && path == tmpl.path {
+			p.skip(path)
+			continue
+		}
+
+		if other, ok := rendered[path]; ok {
+			p.addError(tmpl.path, PhaseWrite, fmt.Errorf("path %q conflicts with %q", path, other), nil)
+			continue
+		}
+		rendered[path] = tmpl.path
+
+		if path == tmpl.path {
+			p.logVerbose("processing %q", path)
+		} else {
+			p.logVerbose("processing %q into %q", tmpl.path, path)
+			moved = append(moved, tmpl.path)
+		}
+
+		// Render into memory first so a failed template does not truncate its output.
+		var buf bytes.Buffer
+		reset(path, tmpl.t)
+		if execErr := tmpl.t.Execute(&buf, nil); execErr != nil {
+			if err = canceled(ctx, execErr); err != nil {
+				// Canceled while prompting, so do not report an error for this template.
+				break
+			}
+			p.addError(tmpl.path, PhaseExecute, execErr, tmpl.source)
+			continue
+		}
+
+		action := ActionRewrite
+		if _, statErr := p.dstFS.Stat(path); errors.Is(statErr, fs.ErrNotExist) {
+			action = ActionCreate
+		}
+
+		tx.write(path, buf.Bytes())
+		p.result.Templates = append(p.result.Templates, path)
+		p.Plan.add(path, action, tmpl.source, buf.Bytes())
+
+		if deleteFiles {
+			allFilesToDelete = append(allFilesToDelete, filesToDelete...)
+		}
+		allFilesToMove = append(allFilesToMove, filesToMove...)
+		for _, f := range filesToEmit {
+			allFilesToEmit = append(allFilesToEmit, emittedFile{f, tmpl.path})
+		}
+	}
+
+	// Templates are no longer executing so files cannot be emitted.
+	reset("", nil)
+
+	// Stop if canceled unless partial results should be kept.
+	canceled := err
+	if canceled != nil && !p.KeepPartial {
+		return p.result, canceled
+	}
+
+	// Stage emitted files before files to delete or move so they can be deleted or moved too.
+	for _, f := range allFilesToEmit {
+		if other, ok := rendered[f.Path]; ok {
+			p.addError(f.template, PhaseWrite, fmt.Errorf("path %q conflicts with %q", f.Path, other), nil)
+			continue
+		}
+		rendered[f.Path] = f.template
+
+		action := ActionRewrite
+		if _, statErr := p.dstFS.Stat(f.Path); errors.Is(statErr, fs.ErrNotExist) {
+			action = ActionCreate
+		}
+
+		p.logVerbose("emitting %q", f.Path)
+		tx.write(f.Path, f.Content)
+		p.result.Emitted = append(p.result.Emitted, f.Path)
+		p.Plan.add(f.Path, action, nil, f.Content)
+	}
+
+	if err = p.stageAnswers(tx, answers, resolver.Resolved); err != nil {
+		p.addError(p.answersPath, PhaseWrite, err, nil)
+	}
+
+	// Stage files to delete that should exist in the destination FS, or will after committing.
+	for _, fileToDelete := range allFilesToDelete {
+		if tx.deleted(fileToDelete) {
+			continue
+		}
+
+		source, ok := tx.staged(fileToDelete)
+		if !ok {
+			if source, err = afero.ReadFile(p.dstFS, fileToDelete); err != nil {
+				p.addError(fileToDelete, PhaseDelete, err, nil)
+				continue
+			}
+		}
+
+		tx.delete(fileToDelete)
+		p.Plan.add(fileToDelete, ActionDelete, source, nil)
+	}
+
+	// Delete the originals of moved templates e.g., when processing templates in place,
+	// unless another template was rendered to the same path.
+	for _, original := range moved {
+		if _, ok := rendered[original]; ok || tx.deleted(original) {
+			continue
+		}
+
+		source, err := afero.ReadFile(p.dstFS, original)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			p.addError(original, PhaseDelete, err, nil)
+			continue
+		}
 
-	funcs := template.FuncMap{
-		"param":      functions.ParamFunc(p.Stdin, p.Stderr, p.IsTTY, params),
+		tx.delete(original)
+		tx.prune(path.Dir(original))
+		p.Plan.add(original, ActionDelete, source, nil)
+	}
+
+	p.stageMoves(tx, allFilesToMove)
+
+	p.Plan.mask(p.secrets)
+	if p.Diff != nil {
+		if err = p.Plan.WriteDiff(p.Diff); err != nil {
+			return p.result, err
+		}
+	}
+
+	if p.DryRun {
+		p.result.Deleted = append(p.result.Deleted, tx.deletes...)
+	} else if len(p.result.Errors) == 0 || p.KeepPartial {
+		p.commit(tx)
+	} else {
+		p.logVerbose("not writing changes because of errors")
+	}
+
+	if canceled != nil {
+		return p.result, canceled
+	}
+
+	if len(p.result.Errors) == 0 {
+		return p.result, nil
+	}
+
+	return p.result, Errors(p.result.Errors)
+}
+
+// funcs returns all template functions using the given param and file functions.
+func (p *Processor) funcs(param, deleteFile, moveFile, renameFile, emitFile any) template.FuncMap {
+	return template.FuncMap{
+		"param":      param,
 		"lowercase":  functions.LowercaseFunc(*p.Language),
 		"titlecase":  functions.TitlecaseFunc(*p.Language),
 		"uppercase":  functions.UppercaseFunc(*p.Language),
 		"pluralize":  functions.PluralizeFunc,
 		"replace":    functions.Replace,
+		"choice":     functions.Choice,
+		"list":       functions.NewList,
+		"secret":     functions.NewSecret,
+		"when":       functions.When,
 		"date":       functions.DateFunc,
 		"true":       func() bool { return true },
 		"false":      func() bool { return false },
-		"deleteFile": functions.DeleteFunc(&current, &deleteFiles, &filesToDelete),
+		"deleteFile": deleteFile,
+		"moveFile":   moveFile,
+		"renameFile": renameFile,
+		"emitFile":   emitFile,
 	}
+}
+
+// emittedFile is a file written by emitFile from a template.
+type emittedFile struct {
+	functions.File
+	template string
+}
+
+// parsedTemplate is a template parsed from a file.
+type parsedTemplate struct {
+	path   string
+	source []byte
+	t      *template.Template
+	raw    bool // Whether the content is not a template but the path may be.
+}
+
+// parse parses all templates under root. Files that could not be parsed are recorded as errors.
+func (p *Processor) parse(ctx context.Context, root string, funcs template.FuncMap) ([]*parsedTemplate, error) {
+	var templates []*parsedTemplate
 
 	// cspell:ignore IOFS
 	dir := afero.NewIOFS(p.srcFS)
 
 	err := fs.WalkDir(dir, root, func(path string, d fs.DirEntry, err error) (_ error) {
-		// TODO: Bubble up errors to channel but carry on with as many files as possible.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		// Record errors but carry on with as many files as possible.
 		if err != nil {
-			p.logWarning("failed to walk %q: %v\n", path, err)
+			p.addError(path, PhaseRead, err, nil)
 			return
 		}
 
@@ -113,71 +429,94 @@ func (p *Processor) Execute(root string, params map[string]string) error {
 			return fs.SkipDir
 		case p.exclude(path):
 			p.logVerbose("skipping %q", path)
+			p.result.Excluded = append(p.result.Excluded, path)
+			p.Plan.add(path, ActionSkip, nil, nil)
 			if d.IsDir() {
 				return fs.SkipDir
 			}
 			return
 		case d.IsDir():
 			return
+		case p.manifest != nil && path == p.manifest.path:
+			p.logVerbose("skipping manifest %q", path)
+			return
+		case path == p.answersPath:
+			p.logVerbose("skipping answers %q", path)
+			return
 		}
-		p.logVerbose("processing %q", path)
+		p.logVerbose("parsing %q", path)
 
 		t := template.New(d.Name()).Funcs(funcs)
 		if p.LeftDelim != "" && p.RightDelim != "" {
 			t = t.Delims(p.LeftDelim, p.RightDelim)
 		}
 
-		t, err = t.ParseFS(dir, path)
+		var source []byte
+		source, err = afero.ReadFile(p.srcFS, path)
 		if err != nil {
-			p.logWarning("failed to parse %q: %v\n", path, err)
-			return
-		}
-
-		if !isTemplate(t) {
-			p.logVerbose("skipping non-template %q", path)
+			p.addError(path, PhaseRead, err, nil)
 			return
 		}
 
-		var file afero.File
-		file, err = p.dstFS.Create(path)
+		t, err = t.Parse(string(source))
 		if err != nil {
-			p.logWarning("failed to create output %q: %v\n", path, err)
+			p.addError(path, PhaseParse, err, source)
 			return
 		}
 
-		reset(path)
-		err = t.Execute(file, nil)
-		file.Close()
-
-		if err != nil {
-			p.logWarning("failed to process %q: %v\n", path, err)
+		// Files with templated paths may need to be moved even if their content is not a template.
+		raw := !isTemplate(t)
+		if raw && !p.isTemplatePath(path) {
+			p.skip(path)
 			return
 		}
 
-		if deleteFiles {
-			allFilesToDelete = append(allFilesToDelete, filesToDelete...)
-		}
+		templates = append(templates, &parsedTemplate{
+			path:   path,
+			source: source,
+			t:      t,
+			raw:    raw,
+		})
 
 		return
 	})
 
-	if err != nil {
-		return err
+	return templates, err
+}
+
+// canceled returns ctx.Err() if ctx is done, or context.Canceled if err is because a user canceled a prompt
+// e.g., pressing Ctrl+C in raw mode, which does not signal the process; otherwise, it returns nil.
+func canceled(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	} else if errors.Is(err, context.Canceled) {
+		return context.Canceled
 	}
+	return nil
+}
 
-	// Delete files that should now exist in the destination FS.
-	for _, fileToDelete := range allFilesToDelete {
-		p.logVerbose("deleting %q", fileToDelete)
-		if err = p.dstFS.Remove(fileToDelete); err != nil {
-			p.logWarning("failed to delete %q: %v\n", fileToDelete, err)
+// skip records that path is not a template.
+func (p *Processor) skip(path string) {
+	p.logVerbose("skipping non-template %q", path)
+	p.result.Skipped = append(p.result.Skipped, path)
+	p.Plan.add(path, ActionSkip, nil, nil)
+}
+
+func (p *Processor) write(name string, content []byte) error {
+	if dir := path.Dir(name); dir != "." {
+		if err := p.dstFS.MkdirAll(dir, 0755); err != nil {
+			return err
 		}
 	}
 
-	if p.errors == 0 {
-		return nil
+	file, err := p.dstFS.Create(name)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	return fmt.Errorf("failed to process %s", functions.Pluralize(p.errors, "template"))
+	_, err = file.Write(content)
+	return err
 }
 
 func (p *Processor) logVerbose(format string, v ...any) {
@@ -186,11 +525,10 @@ func (p *Processor) logVerbose(format string, v ...any) {
 	}
 }
 
-func (p *Processor) logWarning(format string, v ...any) {
-	p.errors++
-	if p.Log != nil {
-		p.Log.Printf(format, v...)
-	}
+// addError records a TemplateError for path to return when finished.
+// Pass the template source to find the position of parse and execute errors.
+func (p *Processor) addError(path string, phase Phase, err error, source []byte) {
+	p.result.Errors = append(p.result.Errors, newTemplateError(path, phase, err, source))
 }
 
 func (p *Processor) exclude(s string) bool {
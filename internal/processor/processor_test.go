@@ -5,7 +5,10 @@ package processor
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"text/template"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/heaths/go-console"
+	"github.com/heaths/go-template/internal/functions"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -96,7 +100,7 @@ func TestProcessor_Execute(t *testing.T) {
 				"github.owner": "heaths",
 				"github.repo":  "template-golang",
 			}
-			err = proc.Execute(".", params)
+			_, err = proc.Execute(context.Background(), ".", params)
 			assert.NoError(t, err, "failed to process template")
 
 			_, err = dstFS.Stat(".git")
@@ -180,7 +184,7 @@ func TestProcessor_Execute_delete(t *testing.T) {
 				"github.repo":  "template-golang",
 				"release":      strconv.FormatBool(tt.release),
 			}
-			err = proc.Execute(".", params)
+			_, err = proc.Execute(context.Background(), ".", params)
 			assert.NoError(t, err, "failed to process template")
 
 			_, err = dstFS.Stat(".git")
@@ -207,6 +211,688 @@ func TestProcessor_Execute_delete(t *testing.T) {
 	}
 }
 
+func TestProcessor_Execute_dryRun(t *testing.T) {
+	t.Parallel()
+
+	con := console.Fake(
+		console.WithStdin(bytes.NewBufferString("template\n")),
+		console.WithStderrTTY(true),
+	)
+
+	const release = "{{deleteFile \"CHANGELOG.md\"}}name: {{param \"name\"}}"
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, srcFS.Mkdir("build", 0755))
+	require.NoError(t, afero.WriteFile(srcFS, "build/dat", []byte{00, 01, 02, 03}, 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "release.yml", []byte(release), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "CHANGELOG.md", []byte("# Changes"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "README.md", []byte("not a template"), 0644))
+
+	plan := new(Plan)
+	proc := Processor{
+		Stderr: con.Stderr(),
+		Stdin:  con.Stdin(),
+		IsTTY:  con.IsStderrTTY(),
+
+		Exclusions: []string{"build"},
+		DryRun:     true,
+		Plan:       plan,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	_, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.NoError(t, err, "failed to process template")
+
+	want := []Change{
+		{Path: "CHANGELOG.md", Action: ActionSkip},
+		{Path: "README.md", Action: ActionSkip},
+		{Path: "build", Action: ActionSkip},
+		{Path: "release.yml", Action: ActionRewrite, Source: []byte(release), Content: []byte("name: template")},
+		{Path: "CHANGELOG.md", Action: ActionDelete, Source: []byte("# Changes")},
+	}
+	assert.Equal(t, want, plan.Changes)
+
+	got, err := afero.ReadFile(srcFS, "release.yml")
+	require.NoError(t, err)
+	assert.Equal(t, release, string(got))
+
+	_, err = srcFS.Stat("CHANGELOG.md")
+	assert.NoError(t, err, "CHANGELOG.md should exist")
+}
+
+func TestProcessor_Execute_diff(t *testing.T) {
+	t.Parallel()
+
+	con := console.Fake(
+		console.WithStdin(bytes.NewBufferString("template\n")),
+		console.WithStderrTTY(true),
+	)
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "release.yml", []byte("{{deleteFile \"CHANGELOG.md\"}}name: {{param \"name\"}}\n"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "CHANGELOG.md", []byte("# Changes\n"), 0644))
+
+	var diff bytes.Buffer
+	proc := Processor{
+		Stderr: con.Stderr(),
+		Stdin:  con.Stdin(),
+		IsTTY:  con.IsStderrTTY(),
+
+		DryRun: true,
+		Diff:   &diff,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	_, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.NoError(t, err, "failed to process template")
+
+	want := heredoc.Doc(`
+		--- a/release.yml
+		+++ b/release.yml
+		@@ -1 +1 @@
+		-{{deleteFile "CHANGELOG.md"}}name: {{param "name"}}
+		+name: template
+		--- a/CHANGELOG.md
+		+++ /dev/null
+		@@ -1 +0,0 @@
+		-# Changes
+	`)
+	assert.Equal(t, want, diff.String())
+}
+
+func TestProcessor_Execute_result(t *testing.T) {
+	t.Parallel()
+
+	con := console.Fake(
+		console.WithStdin(bytes.NewBufferString("")),
+		console.WithStderrTTY(false),
+	)
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, srcFS.Mkdir("build", 0755))
+	require.NoError(t, afero.WriteFile(srcFS, "build/dat", []byte{00, 01, 02, 03}, 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte("# {{param \"name\"}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte("{{param \"missing\"}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "c.md", []byte("not a template"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "d.md", []byte("{{deleteFile \"c.md\"}}"), 0644))
+
+	proc := Processor{
+		Stderr: con.Stderr(),
+		Stdin:  con.Stdin(),
+		IsTTY:  con.IsStderrTTY(),
+
+		Exclusions: []string{"build"},
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	params := map[string]string{
+		"name": "template",
+	}
+	result, err := proc.Execute(context.Background(), ".", params)
+	assert.EqualError(t, err, `b.md:1:3: failed to execute: executing "b.md" at <param "missing">: error calling param: cannot prompt for parameter "missing"`)
+	require.NotNil(t, result)
+
+	assert.Equal(t, []string{"a.md", "d.md"}, result.Templates)
+	assert.Equal(t, []string{"c.md"}, result.Skipped)
+	assert.Equal(t, []string{"build"}, result.Excluded)
+	assert.Empty(t, result.Deleted)
+	assert.Equal(t, params, result.Params)
+
+	require.Len(t, result.Errors, 1)
+	var templateErr *TemplateError
+	require.ErrorAs(t, result.Errors[0], &templateErr)
+	assert.Equal(t, "b.md", templateErr.Path)
+	assert.Equal(t, PhaseExecute, templateErr.Phase)
+
+	// Nothing should be written if any template failed.
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# {{param \"name\"}}", string(got))
+
+	_, err = srcFS.Stat("c.md")
+	assert.NoError(t, err, "c.md should exist")
+}
+
+func TestProcessor_Execute_keepPartial(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte("# {{param \"name\"}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte("{{param \"missing\"}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "c.md", []byte("not a template"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "d.md", []byte("{{deleteFile \"c.md\"}}"), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		KeepPartial: true,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"name": "template"})
+	assert.Error(t, err)
+	assert.Len(t, result.Errors, 1)
+	assert.Equal(t, []string{"c.md"}, result.Deleted)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# template", string(got))
+
+	_, err = srcFS.Stat("c.md")
+	assert.Error(t, err, "c.md should not exist")
+}
+
+// failFs fails to create or remove a specific file.
+type failFs struct {
+	afero.Fs
+	path string
+}
+
+func (fs failFs) Create(name string) (afero.File, error) {
+	if name == fs.path {
+		return nil, os.ErrPermission
+	}
+	return fs.Fs.Create(name)
+}
+
+func (fs failFs) Remove(name string) error {
+	if name == fs.path {
+		return os.ErrPermission
+	}
+	return fs.Fs.Remove(name)
+}
+
+func TestProcessor_Execute_rollback(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte("# {{param \"name\"}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte("{{deleteFile \"c.md\" \"d.md\"}}b"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "c.md", []byte("c"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "d.md", []byte("d"), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		srcFS: srcFS,
+		dstFS: failFs{srcFS, "d.md"},
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"name": "template"})
+	require.Error(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, result.Errors[0], os.ErrPermission)
+	assert.Empty(t, result.Deleted)
+
+	for name, want := range map[string]string{
+		"a.md": "# {{param \"name\"}}",
+		"b.md": "{{deleteFile \"c.md\" \"d.md\"}}b",
+		"c.md": "c",
+		"d.md": "d",
+	} {
+		got, err := afero.ReadFile(srcFS, name)
+		require.NoError(t, err, "%s should exist", name)
+		assert.Equal(t, want, string(got))
+	}
+}
+
+func TestProcessor_Execute_canceled(t *testing.T) {
+	t.Parallel()
+
+	// The pipe is never written so reads block until canceled.
+	stdin, w := io.Pipe()
+	defer w.Close()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte("# {{param \"name\"}}"), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, "b.md", []byte("{{deleteFile}}"), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  stdin,
+		IsTTY:  true,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	result, err := proc.Execute(ctx, ".", make(map[string]string))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, result.Templates)
+	assert.Empty(t, result.Errors)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# {{param \"name\"}}", string(got))
+
+	_, err = srcFS.Stat("b.md")
+	assert.NoError(t, err, "b.md should exist")
+}
+
+// canceledPrompter cancels every prompt like pressing Ctrl+C in a menu.
+type canceledPrompter struct {
+	functions.Prompter
+	asked int
+}
+
+func (p *canceledPrompter) String(context.Context, *functions.Prompt, string) (string, error) {
+	p.asked++
+	return "", context.Canceled
+}
+
+func TestProcessor_Execute_canceledPrompt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{
+			name:     "collected",
+			template: `{{param "name"}}`,
+		},
+		{
+			name:     "executed",
+			template: `{{if true}}{{param "name"}}{{end}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcFS := afero.NewMemMapFs()
+			for _, name := range []string{"a.md", "b.md", "c.md"} {
+				require.NoError(t, afero.WriteFile(srcFS, name, []byte(tt.template), 0644))
+			}
+
+			prompter := &canceledPrompter{}
+			proc := Processor{
+				Stderr:   io.Discard,
+				Prompter: prompter,
+
+				srcFS: srcFS,
+				dstFS: srcFS,
+			}
+			proc.Initialize()
+
+			// The run is aborted even though the context is not done.
+			result, err := proc.Execute(context.Background(), ".", make(map[string]string))
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Empty(t, result.Templates)
+			assert.Empty(t, result.Errors)
+			assert.Equal(t, 1, prompter.asked)
+		})
+	}
+}
+
+func TestProcessor_Execute_paramsFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "params.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"name": "json", "github": {"owner": "heaths", "repo": "json"}}`), 0644))
+	envFile := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte("github.repo=template\n"), 0644))
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "name"}} {{param "github.owner"}}/{{param "github.repo"}} {{param "year"}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString("year = 2022"),
+
+		ParamsFiles: []string{jsonFile, envFile, "-"},
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	// Supplied parameters take precedence over files, and later files over earlier files.
+	_, err := proc.Execute(context.Background(), ".", map[string]string{"name": "template"})
+	require.NoError(t, err)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "template heaths/template 2022", string(got))
+}
+
+func TestProcessor_Execute_list(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "params.yaml")
+	require.NoError(t, os.WriteFile(yamlFile, []byte("arch: [arm64]\n"), 0644))
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{range param "os" (list "linux" "windows")}}{{.}} {{end}}{{range param "arch" (list "amd64" "arm64")}}{{.}}{{end}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		ParamsFiles: []string{yamlFile},
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	_, err := proc.Execute(context.Background(), ".", map[string]string{"os": "Windows, linux"})
+	require.NoError(t, err)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "windows linux arm64", string(got))
+}
+
+func TestProcessor_Execute_listReference(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(heredoc.Doc(`
+		{{range param "os" (list "linux" "windows")}}{{.}} {{end}}
+		{{range param "arch" (list "amd64" "arm64")}}{{.}} {{end}}
+		{{range param "os"}}{{.}} {{end}}
+		{{range param "arch"}}{{.}} {{end}}
+	`)), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		AcceptDefaults: true,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	_, err := proc.Execute(context.Background(), ".", map[string]string{"os": "windows,linux"})
+	require.NoError(t, err)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "windows linux \namd64 arm64 \nwindows linux \namd64 arm64 \n", string(got))
+}
+
+func TestProcessor_Execute_paramsFiles_invalid(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "params.json")
+	require.NoError(t, os.WriteFile(name, []byte(`{"name": `), 0644))
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "name"}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		ParamsFiles: []string{name, filepath.Join(t.TempDir(), "missing.json")},
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", nil)
+	require.Error(t, err)
+	require.Len(t, result.Errors, 1)
+
+	var templateErr *TemplateError
+	require.ErrorAs(t, result.Errors[0], &templateErr)
+	assert.Equal(t, name, templateErr.Path)
+	assert.Equal(t, PhaseParse, templateErr.Phase)
+	assert.Empty(t, result.Templates)
+}
+
+func TestProcessor_Execute_envPrefix(t *testing.T) {
+	// Cannot run in parallel with t.Setenv.
+	t.Setenv("GO_TEMPLATE_TEST_GITHUB_OWNER", "heaths")
+	t.Setenv("GO_TEMPLATE_TEST_NAME", "ignored")
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "name"}} {{param "github.owner"}}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		EnvPrefix: "GO_TEMPLATE_TEST",
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"name": "template"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "template", "github.owner": "heaths"}, result.Params)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "template heaths", string(got))
+}
+
+func TestProcessor_Execute_answers(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "name"}} {{param "copyright" 2022}}`), 0644))
+	dstFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(dstFS, DefaultAnswersFile, []byte(`{"copyright": "2021", "removed": "value"}`), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString("template\n"),
+		IsTTY:  true,
+
+		AnswersFile: DefaultAnswersFile,
+
+		srcFS: srcFS,
+		dstFS: dstFS,
+	}
+	proc.Initialize()
+
+	// Previous answers are used instead of prompting, and kept even if no longer used.
+	_, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.NoError(t, err)
+
+	got, err := afero.ReadFile(dstFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "template 2021", string(got))
+
+	got, err = afero.ReadFile(dstFS, DefaultAnswersFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "template", "copyright": "2021", "removed": "value"}`, string(got))
+
+	// Run again without a TTY to make sure nothing is prompted.
+	proc.Stdin = bytes.NewBufferString("")
+	proc.IsTTY = false
+
+	_, err = proc.Execute(context.Background(), ".", map[string]string{"copyright": "2023"})
+	require.NoError(t, err)
+
+	got, err = afero.ReadFile(dstFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "template 2023", string(got))
+
+	got, err = afero.ReadFile(dstFS, DefaultAnswersFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "template", "copyright": "2023", "removed": "value"}`, string(got))
+}
+
+func TestProcessor_Execute_noAnswers(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{"template" | titlecase}}`), 0644))
+	dstFS := afero.NewMemMapFs()
+
+	proc := Processor{
+		Stderr: io.Discard,
+
+		AnswersFile: DefaultAnswersFile,
+
+		srcFS: srcFS,
+		dstFS: dstFS,
+	}
+	proc.Initialize()
+
+	// No answers are written if no parameters were resolved.
+	_, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.NoError(t, err)
+
+	got, err := afero.ReadFile(dstFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "Template", string(got))
+
+	exists, err := afero.Exists(dstFS, DefaultAnswersFile)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestProcessor_Execute_secret(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, ".npmrc", []byte(`//registry.npmjs.org/:_authToken={{param "token" (secret)}}`+"\n"), 0644))
+
+	var plan Plan
+	var diff bytes.Buffer
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString("s3cr3t\n"),
+		IsTTY:  true,
+
+		DryRun:      true,
+		Plan:        &plan,
+		Diff:        &diff,
+		AnswersFile: DefaultAnswersFile,
+
+		srcFS: srcFS,
+		dstFS: srcFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", make(map[string]string))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", result.Params["token"])
+
+	// Secrets are masked in the plan and not saved with answers.
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, "//registry.npmjs.org/:_authToken=********\n", string(plan.Changes[0].Content))
+	assert.NotContains(t, diff.String(), "s3cr3t")
+}
+
+func TestProcessor_Execute_gitDefaults(t *testing.T) {
+	t.Parallel()
+
+	global := filepath.Join(t.TempDir(), ".gitconfig")
+	require.NoError(t, os.WriteFile(global, []byte("[user]\n\tname = Heath Stewart\n\temail = heaths@example.com\n"), 0644))
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "a.md", []byte(`{{param "git.name"}} <{{param "git.email"}}> {{param "github.owner"}}/{{param "github.repo"}}`), 0644))
+	require.NoError(t, afero.WriteFile(srcFS, ".git/config", []byte("[user]\n\temail = heaths@example.org\n[remote \"origin\"]\n\turl = git@github.com:heaths/go-template.git\n"), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		IsTTY:  false,
+
+		GitDefaults:    true,
+		NonInteractive: true,
+		AcceptDefaults: true,
+
+		srcFS:      srcFS,
+		dstFS:      srcFS,
+		gitConfigs: []string{filepath.Join(t.TempDir(), "missing"), global},
+	}
+	proc.Initialize()
+
+	// The repo config takes precedence over global config.
+	_, err := proc.Execute(context.Background(), ".", map[string]string{"github.repo": "template"})
+	require.NoError(t, err)
+
+	got, err := afero.ReadFile(srcFS, "a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "Heath Stewart <heaths@example.org> heaths/template", string(got))
+}
+
+func TestProcessor_Execute_emitFile(t *testing.T) {
+	t.Parallel()
+
+	srcFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(srcFS, "services.md", []byte(heredoc.Doc(`
+		{{- define "doc" -}}
+		// Package {{.}} implements the {{.}} service.
+		package {{.}}
+		{{end -}}
+		# Services
+		{{range param "services" (list)}}
+		* {{.}}{{emitFile (printf "internal/%s/doc.go" .) "doc" .}}
+		{{- end}}
+	`)), 0644))
+	dstFS := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(dstFS, "internal/users/doc.go", []byte("package users\n"), 0644))
+
+	var plan Plan
+	proc := Processor{
+		Stderr: io.Discard,
+		Plan:   &plan,
+
+		srcFS: srcFS,
+		dstFS: dstFS,
+	}
+	proc.Initialize()
+
+	result, err := proc.Execute(context.Background(), ".", map[string]string{"services": "orders,users"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"services.md"}, result.Templates)
+	assert.Equal(t, []string{"internal/orders/doc.go", "internal/users/doc.go"}, result.Emitted)
+
+	got, err := afero.ReadFile(dstFS, "services.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Services\n\n* orders\n* users\n", string(got))
+
+	got, err = afero.ReadFile(dstFS, "internal/orders/doc.go")
+	require.NoError(t, err)
+	assert.Equal(t, "// Package orders implements the orders service.\npackage orders\n", string(got))
+
+	require.Len(t, plan.Changes, 3)
+	assert.Equal(t, ActionCreate, plan.Changes[1].Action)
+	assert.Equal(t, ActionRewrite, plan.Changes[2].Action)
+
+	var diff bytes.Buffer
+	require.NoError(t, plan.WriteDiff(&diff))
+	assert.Contains(t, diff.String(), heredoc.Doc(`
+		--- /dev/null
+		+++ b/internal/orders/doc.go
+		@@ -0,0 +1,2 @@
+		+// Package orders implements the orders service.
+		+package orders
+	`))
+
+	// Emitting the same path twice conflicts.
+	_, err = proc.Execute(context.Background(), ".", map[string]string{"services": "orders,orders"})
+	assert.EqualError(t, err, `services.md: failed to write: path "internal/orders/doc.go" conflicts with "services.md"`)
+}
+
 func TestIsTemplate(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,16 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+// Result describes which files were processed and the parameters used.
+type Result struct {
+	Templates []string          // Templates that were processed, or files that were merged when upgrading.
+	Emitted   []string          // Files that were written by emitFile.
+	Skipped   []string          // Files that were skipped because they were not templates.
+	Excluded  []string          // Directories and files that were excluded.
+	Deleted   []string          // Files that were deleted by deleteFile, or moved.
+	Conflicts []string          // Files with changes that conflicted when upgrading.
+	Params    map[string]string // Final parameter values including any prompted values.
+	Errors    []error           // Errors for individual files as *TemplateError.
+}
@@ -0,0 +1,188 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+
+	"github.com/spf13/afero"
+)
+
+// transaction stages rendered templates and files to delete so they can be committed together.
+type transaction struct {
+	writes  []stagedFile
+	deletes []string
+	prunes  []string
+	backups []backup
+}
+
+type stagedFile struct {
+	path    string
+	content []byte
+}
+
+// backup is the original content of a file before it was written or deleted.
+type backup struct {
+	path    string
+	content []byte
+	existed bool
+}
+
+func (tx *transaction) write(path string, content []byte) {
+	tx.writes = append(tx.writes, stagedFile{path, content})
+}
+
+func (tx *transaction) delete(path string) {
+	tx.deletes = append(tx.deletes, path)
+}
+
+// unstage removes path from the files to write.
+func (tx *transaction) unstage(path string) {
+	writes := tx.writes[:0]
+	for _, f := range tx.writes {
+		if f.path != path {
+			writes = append(writes, f)
+		}
+	}
+	tx.writes = writes
+}
+
+// undelete removes path from the files to delete.
+func (tx *transaction) undelete(path string) {
+	deletes := tx.deletes[:0]
+	for _, p := range tx.deletes {
+		if p != path {
+			deletes = append(deletes, p)
+		}
+	}
+	tx.deletes = deletes
+}
+
+// prune removes dir and its parents after deleting files if they are empty e.g., a templated directory name.
+func (tx *transaction) prune(dir string) {
+	tx.prunes = append(tx.prunes, dir)
+}
+
+// staged returns the content of path if it was staged to be written.
+func (tx *transaction) staged(path string) ([]byte, bool) {
+	for _, f := range tx.writes {
+		if f.path == path {
+			return f.content, true
+		}
+	}
+	return nil, false
+}
+
+func (tx *transaction) deleted(path string) bool {
+	for _, p := range tx.deletes {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// commit writes and deletes all staged files in the destination.
+// If any file cannot be written or deleted, all previous changes are rolled back
+// unless partial results should be kept. Returns true if all changes were committed.
+func (p *Processor) commit(tx *transaction) bool {
+	failed := func(path string, phase Phase, err error) bool {
+		p.addError(path, phase, err, nil)
+		if p.KeepPartial {
+			return false
+		}
+
+		p.rollback(tx)
+		return true
+	}
+
+	for _, f := range tx.writes {
+		if err := p.backup(tx, f.path); err != nil {
+			if failed(f.path, PhaseWrite, err) {
+				return false
+			}
+			continue
+		}
+
+		if err := p.write(f.path, f.content); err != nil {
+			if failed(f.path, PhaseWrite, err) {
+				return false
+			}
+		}
+	}
+
+	for _, path := range tx.deletes {
+		p.logVerbose("deleting %q", path)
+		if err := p.backup(tx, path); err != nil {
+			if failed(path, PhaseDelete, err) {
+				return false
+			}
+			continue
+		}
+
+		if err := p.dstFS.Remove(path); err != nil {
+			if failed(path, PhaseDelete, err) {
+				return false
+			}
+			continue
+		}
+		p.result.Deleted = append(p.result.Deleted, path)
+	}
+
+	for _, dir := range tx.prunes {
+		for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if empty, err := afero.IsEmpty(p.dstFS, dir); err != nil || !empty {
+				break
+			}
+
+			p.logVerbose("removing %q", dir)
+			if err := p.dstFS.Remove(dir); err != nil {
+				break
+			}
+		}
+	}
+
+	return true
+}
+
+func (p *Processor) backup(tx *transaction, path string) error {
+	content, err := afero.ReadFile(p.dstFS, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		tx.backups = append(tx.backups, backup{path: path})
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	tx.backups = append(tx.backups, backup{
+		path:    path,
+		content: content,
+		existed: true,
+	})
+	return nil
+}
+
+// rollback restores backups in reverse order so files changed more than once end up with their original content.
+func (p *Processor) rollback(tx *transaction) {
+	for i := len(tx.backups) - 1; i >= 0; i-- {
+		b := tx.backups[i]
+		p.logVerbose("restoring %q", b.path)
+
+		var err error
+		if b.existed {
+			err = p.write(b.path, b.content)
+		} else {
+			err = p.dstFS.Remove(b.path)
+		}
+
+		if err != nil && p.Log != nil {
+			p.Log.Printf("failed to restore %q: %v", b.path, err)
+		}
+	}
+
+	tx.backups = nil
+	p.result.Deleted = nil
+}
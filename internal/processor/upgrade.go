@@ -0,0 +1,305 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/heaths/go-template/internal/diff"
+	"github.com/spf13/afero"
+	"golang.org/x/exp/maps"
+)
+
+// Upgrade renders the templates in oldRoot and newRoot with the answers saved in project, and merges
+// the changes between them into project. Changes that conflict with changes made in project are
+// surrounded by conflict markers and recorded in Result.Conflicts.
+func (p *Processor) Upgrade(ctx context.Context, oldRoot, newRoot, project string, params map[string]string) (*Result, error) {
+	if params == nil {
+		params = make(map[string]string)
+	}
+
+	p.result = new(Result)
+	p.secrets = make(map[string]string)
+	defer func() {
+		p.result.Params = maps.Clone(params)
+	}()
+
+	if err := p.loadParamsFiles(params); err != nil {
+		p.result.Errors = append(p.result.Errors, err)
+		return p.result, Errors(p.result.Errors)
+	}
+
+	answers, err := p.loadAnswers(project)
+	if err != nil {
+		p.result.Errors = append(p.result.Errors, err)
+		return p.result, Errors(p.result.Errors)
+	}
+	answersPath := p.answersPath
+
+	// Read git config from the project since templates are rendered in memory.
+	p.gitDefaults = p.loadGitDefaults(project)
+
+	// Render the old template first since all its parameters should have been answered.
+	oldFS, err := p.render(ctx, oldRoot, params, answers)
+	if err != nil {
+		return p.result, err
+	}
+
+	newFS, err := p.render(ctx, newRoot, params, answers)
+	if err != nil {
+		return p.result, err
+	}
+
+	paths, err := renderedPaths(oldFS, newFS)
+	if err != nil {
+		return p.result, err
+	}
+
+	tx := new(transaction)
+	for _, name := range paths {
+		if err = ctx.Err(); err != nil {
+			return p.result, err
+		}
+		p.merge(tx, project, name, oldFS, newFS)
+	}
+
+	// Save answers to any new parameters.
+	if answersPath != "" {
+		if content, err := afero.ReadFile(newFS, p.renderedAnswersFile()); err == nil {
+			action := ActionRewrite
+			source, err := afero.ReadFile(p.dstFS, answersPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				action = ActionCreate
+			}
+
+			if !bytes.Equal(source, content) {
+				tx.write(answersPath, content)
+				p.Plan.add(answersPath, action, source, content)
+			}
+		}
+	}
+
+	p.Plan.mask(p.secrets)
+	if p.Diff != nil {
+		if err = p.Plan.WriteDiff(p.Diff); err != nil {
+			return p.result, err
+		}
+	}
+
+	if p.DryRun {
+		p.result.Deleted = append(p.result.Deleted, tx.deletes...)
+	} else if len(p.result.Errors) == 0 {
+		p.commit(tx)
+	} else {
+		p.logVerbose("not writing changes because of errors")
+	}
+
+	if len(p.result.Errors) == 0 {
+		return p.result, nil
+	}
+
+	return p.result, Errors(p.result.Errors)
+}
+
+// render copies root into memory and processes all templates in place.
+func (p *Processor) render(ctx context.Context, root string, params, answers map[string]string) (afero.Fs, error) {
+	p.logVerbose("rendering %q", root)
+
+	memFS := afero.NewMemMapFs()
+	err := fs.WalkDir(afero.NewIOFS(p.srcFS), root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch {
+		case rel == ".git" || rel == ".hg":
+			return fs.SkipDir
+		case d.IsDir():
+			return memFS.MkdirAll(rel, 0755)
+		}
+
+		content, err := afero.ReadFile(p.srcFS, name)
+		if err != nil {
+			return err
+		}
+		return afero.WriteFile(memFS, rel, content, 0644)
+	})
+	if err != nil {
+		p.result.Errors = append(p.result.Errors, newTemplateError(root, PhaseRead, err, nil))
+		return nil, Errors(p.result.Errors)
+	}
+
+	// Pass saved answers in a file the rendering processor reads and updates.
+	if answers != nil {
+		content, err := marshalAnswers(answers)
+		if err != nil {
+			return nil, err
+		}
+		if err = afero.WriteFile(memFS, p.renderedAnswersFile(), content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	r := Processor{
+		Stderr:      p.Stderr,
+		Stdin:       p.Stdin,
+		IsTTY:       p.IsTTY,
+		Prompter:    p.Prompter,
+		LeftDelim:   p.LeftDelim,
+		RightDelim:  p.RightDelim,
+		Exclusions:  p.Exclusions,
+		Language:    p.Language,
+		Log:         p.Log,
+		Verbose:     p.Verbose,
+		EnvPrefix:   p.EnvPrefix,
+		AnswersFile: p.renderedAnswersFile(),
+		Validators:  p.Validators,
+		GitDefaults: p.GitDefaults,
+
+		NonInteractive: p.NonInteractive,
+		AcceptDefaults: p.AcceptDefaults,
+
+		srcFS:       memFS,
+		dstFS:       memFS,
+		gitConfigs:  p.gitConfigs,
+		gitDefaults: p.gitDefaults,
+	}
+	r.Initialize()
+
+	result, err := r.Execute(ctx, ".", params)
+	maps.Copy(p.secrets, r.secrets)
+	if err != nil {
+		if len(result.Errors) == 0 {
+			return nil, err
+		}
+
+		for _, err := range result.Errors {
+			var templateErr *TemplateError
+			if errors.As(err, &templateErr) {
+				templateErr.Path = path.Join(root, templateErr.Path)
+			}
+			p.result.Errors = append(p.result.Errors, err)
+		}
+		return nil, Errors(p.result.Errors)
+	}
+
+	return memFS, nil
+}
+
+// renderedAnswersFile returns the name of the answers file in rendered templates.
+func (p *Processor) renderedAnswersFile() string {
+	if p.AnswersFile != "" {
+		return p.AnswersFile
+	}
+	return DefaultAnswersFile
+}
+
+// renderedPaths returns the sorted paths of all files in either file system.
+func renderedPaths(fss ...afero.Fs) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, fsys := range fss {
+		err := afero.Walk(fsys, ".", func(name string, info fs.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			seen[filepath.ToSlash(name)] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paths := maps.Keys(seen)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// merge stages the changes to name between oldFS and newFS into project.
+func (p *Processor) merge(tx *transaction, project, name string, oldFS, newFS afero.Fs) {
+	// Answers are saved separately.
+	if name == p.renderedAnswersFile() {
+		return
+	}
+
+	dst := path.Join(project, name)
+	base, baseErr := afero.ReadFile(oldFS, name)
+	theirs, theirsErr := afero.ReadFile(newFS, name)
+	ours, oursErr := afero.ReadFile(p.dstFS, dst)
+	if oursErr != nil && !errors.Is(oursErr, fs.ErrNotExist) {
+		p.addError(dst, PhaseRead, oursErr, nil)
+		return
+	}
+
+	hasBase, hasTheirs, hasOurs := baseErr == nil, theirsErr == nil, oursErr == nil
+	switch {
+	case hasBase && hasTheirs && bytes.Equal(base, theirs):
+		// The template did not change.
+		return
+	case !hasTheirs:
+		// The template removed the file.
+		if !hasOurs {
+			return
+		}
+		if hasBase && bytes.Equal(base, ours) {
+			tx.delete(dst)
+			p.Plan.add(dst, ActionDelete, ours, nil)
+			return
+		}
+		p.logVerbose("keeping %q changed in project but removed from template", dst)
+		p.result.Conflicts = append(p.result.Conflicts, dst)
+		return
+	case !hasOurs:
+		if hasBase {
+			p.logVerbose("not creating %q removed from project but changed in template", dst)
+			p.result.Conflicts = append(p.result.Conflicts, dst)
+			return
+		}
+		tx.write(dst, theirs)
+		p.result.Templates = append(p.result.Templates, dst)
+		p.Plan.add(dst, ActionCreate, nil, theirs)
+		return
+	case bytes.Equal(ours, theirs):
+		return
+	}
+
+	p.logVerbose("merging %q", dst)
+
+	var merged []byte
+	var conflicted bool
+	switch {
+	case bytes.Equal(base, ours):
+		merged = theirs
+	case isBinary(base) || isBinary(ours) || isBinary(theirs):
+		// Keep the project's binary file.
+		p.result.Conflicts = append(p.result.Conflicts, dst)
+		return
+	default:
+		merged, conflicted = diff.Merge(base, ours, theirs, "project", "template")
+	}
+
+	if conflicted {
+		p.result.Conflicts = append(p.result.Conflicts, dst)
+	}
+
+	tx.write(dst, merged)
+	p.result.Templates = append(p.result.Templates, dst)
+	p.Plan.add(dst, ActionRewrite, ours, merged)
+}
+
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Heath Stewart.
+// Licensed under the MIT License. See LICENSE.txt in the project root for license information.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessor_Upgrade(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	for name, content := range map[string]string{
+		"old/a.md": "# {{param \"name\"}}\n\nintro\n\nfooter\n",
+		"old/b.md": "b\n",
+		"old/c.md": "c\n",
+		"old/e.md": "e\n",
+
+		"new/a.md":     "# {{param \"name\"}}\n\nintro\n\nnew footer\n",
+		"new/b.md":     "B\n",
+		"new/d/d.md":   "{{param \"license\" \"MIT\"}}\n",
+		"new/e.md":     "e\n",
+		"new/.git/dat": "ignored",
+
+		"project/a.md":                  "# template\n\nchanged intro\n\nfooter\n",
+		"project/b.md":                  "project\n",
+		"project/c.md":                  "c\n",
+		"project/e.md":                  "project\n",
+		"project/" + DefaultAnswersFile: `{"name": "template"}`,
+	} {
+		require.NoError(t, afero.WriteFile(fs, name, []byte(content), 0644))
+	}
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		AnswersFile: DefaultAnswersFile,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	result, err := proc.Upgrade(context.Background(), "old", "new", "project", map[string]string{"license": "MIT"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"project/a.md", "project/b.md", "project/d/d.md"}, result.Templates)
+	assert.Equal(t, []string{"project/b.md"}, result.Conflicts)
+	assert.Equal(t, []string{"project/c.md"}, result.Deleted)
+
+	for name, want := range map[string]string{
+		"project/a.md":   "# template\n\nchanged intro\n\nnew footer\n",
+		"project/b.md":   "<<<<<<< project\nproject\n=======\nB\n>>>>>>> template\n",
+		"project/d/d.md": "MIT\n",
+		"project/e.md":   "project\n",
+	} {
+		got, err := afero.ReadFile(fs, name)
+		require.NoError(t, err, "%s should exist", name)
+		assert.Equal(t, want, string(got), name)
+	}
+
+	_, err = fs.Stat("project/c.md")
+	assert.Error(t, err, "project/c.md should not exist")
+
+	_, err = fs.Stat("project/.git")
+	assert.Error(t, err, "project/.git should not exist")
+
+	got, err := afero.ReadFile(fs, "project/"+DefaultAnswersFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "template", "license": "MIT"}`, string(got))
+}
+
+func TestProcessor_Upgrade_dryRun(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "old/a.md", []byte("{{param \"name\"}}\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "new/a.md", []byte("# {{param \"name\"}}\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "project/a.md", []byte("template\n"), 0644))
+
+	var diff bytes.Buffer
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		DryRun: true,
+		Diff:   &diff,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	_, err := proc.Upgrade(context.Background(), "old", "new", "project", map[string]string{"name": "template"})
+	require.NoError(t, err)
+
+	want := heredoc.Doc(`
+		--- a/project/a.md
+		+++ b/project/a.md
+		@@ -1 +1 @@
+		-template
+		+# template
+	`)
+	assert.Equal(t, want, diff.String())
+
+	got, err := afero.ReadFile(fs, "project/a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "template\n", string(got))
+}
+
+func TestProcessor_Upgrade_secret(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "old/.npmrc", []byte(`_authToken={{param "token" (secret)}}`+"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "new/.npmrc", []byte(`always-auth=true`+"\n"+`_authToken={{param "token" (secret)}}`+"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "project/.npmrc", []byte("_authToken=s3cr3t\n"), 0644))
+
+	var diff bytes.Buffer
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		DryRun: true,
+		Diff:   &diff,
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	_, err := proc.Upgrade(context.Background(), "old", "new", "project", map[string]string{"token": "s3cr3t"})
+	require.NoError(t, err)
+
+	// The project's current content is masked too.
+	want := heredoc.Doc(`
+		--- a/project/.npmrc
+		+++ b/project/.npmrc
+		@@ -1 +1,2 @@
+		+always-auth=true
+		 _authToken=********
+	`)
+	assert.Equal(t, want, diff.String())
+}
+
+func TestProcessor_Upgrade_error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "old/a.md", []byte("{{param \"name\"}}\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "new/a.md", []byte("{{param \"missing\"}}\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "project/a.md", []byte("template\n"), 0644))
+
+	proc := Processor{
+		Stderr: io.Discard,
+		Stdin:  bytes.NewBufferString(""),
+
+		srcFS: fs,
+		dstFS: fs,
+	}
+	proc.Initialize()
+
+	result, err := proc.Upgrade(context.Background(), "old", "new", "project", map[string]string{"name": "template"})
+	require.Error(t, err)
+	require.Len(t, result.Errors, 1)
+
+	var templateErr *TemplateError
+	require.ErrorAs(t, result.Errors[0], &templateErr)
+	assert.Equal(t, "new/a.md", templateErr.Path)
+}
@@ -4,9 +4,11 @@
 package template
 
 import (
+	"context"
 	"io"
 	"log"
 
+	"github.com/heaths/go-template/internal/functions"
 	"github.com/heaths/go-template/internal/processor"
 	"golang.org/x/text/language"
 )
@@ -14,15 +16,131 @@ import (
 // ApplyOption applies options to the processor.
 type ApplyOption func(*processor.Processor)
 
+// Action describes what was, or would be, done to a file.
+type Action = processor.Action
+
+const (
+	ActionCreate  = processor.ActionCreate  // The file was created in the destination.
+	ActionRewrite = processor.ActionRewrite // The file already existed in the destination and was rewritten.
+	ActionSkip    = processor.ActionSkip    // The file was excluded or not a template.
+	ActionDelete  = processor.ActionDelete  // The file was deleted by deleteFile, or moved.
+)
+
+// Change describes a single change to a file.
+type Change = processor.Change
+
+// Plan records changes in the order they were made.
+type Plan = processor.Plan
+
+// Result describes which files were processed and the parameters used.
+type Result = processor.Result
+
+// Phase describes when an error occurred while processing a file.
+type Phase = processor.Phase
+
+const (
+	PhaseRead    = processor.PhaseRead    // The file could not be read.
+	PhaseParse   = processor.PhaseParse   // The template could not be parsed.
+	PhaseExecute = processor.PhaseExecute // The template could not be executed.
+	PhaseWrite   = processor.PhaseWrite   // The output could not be written.
+	PhaseDelete  = processor.PhaseDelete  // The file could not be deleted.
+	PhaseMove    = processor.PhaseMove    // The file could not be moved.
+)
+
+// TemplateError is an error processing a file with the position in the template, if known.
+// Call Excerpt to get the line of the template with a caret pointing to the error.
+type TemplateError = processor.TemplateError
+
+// DefaultAnswersFile is the conventional name of the file passed to WithAnswersFile.
+const DefaultAnswersFile = processor.DefaultAnswersFile
+
+// Prompter prompts users for parameter values. Pass an implementation to WithPrompter
+// to prompt users with a custom UI. Each method returns the answer, or the default if the
+// user did not answer. If Prompt.Validate returns an error, implementations should show
+// the error and prompt again.
+type Prompter = functions.Prompter
+
+// Prompt describes what to prompt for, including a function to validate answers.
+type Prompt = functions.Prompt
+
 // Apply applies parameters to all templates with the given root directory.
+// Errors for individual files are returned together in an error that unwraps to
+// a *TemplateError for each file. Use ApplyWithResult to get each error in Result.Errors.
 func Apply(root string, params map[string]string, options ...ApplyOption) error {
+	return ApplyContext(context.Background(), root, params, options...)
+}
+
+// ApplyContext applies parameters to all templates with the given root directory like Apply.
+// If ctx is canceled or times out, including while waiting for an answer to a prompt,
+// no more files are processed or deleted and ctx.Err() is returned.
+func ApplyContext(ctx context.Context, root string, params map[string]string, options ...ApplyOption) error {
+	_, err := ApplyWithResultContext(ctx, root, params, options...)
+	return err
+}
+
+// ApplyWithResult applies parameters to all templates with the given root directory
+// and returns a Result describing which files were processed, skipped, excluded, or deleted;
+// the final parameter values; and any errors for individual files.
+// The Result is returned even if an error is returned.
+func ApplyWithResult(root string, params map[string]string, options ...ApplyOption) (*Result, error) {
+	return ApplyWithResultContext(context.Background(), root, params, options...)
+}
+
+// ApplyWithResultContext applies parameters to all templates with the given root directory
+// like ApplyWithResult, and can be canceled like ApplyContext.
+func ApplyWithResultContext(ctx context.Context, root string, params map[string]string, options ...ApplyOption) (*Result, error) {
+	proc := new(processor.Processor)
+	for _, opt := range options {
+		opt(proc)
+	}
+	proc.Initialize()
+
+	return proc.Execute(ctx, root, params)
+}
+
+// Upgrade renders the templates in oldRoot and newRoot - typically checkouts of the template
+// revision a project was generated from and a newer revision - with the answers saved in project
+// by WithAnswersFile and any params, and merges the changes between them into project.
+// Users are prompted only for new parameters.
+//
+// Files the project changed are merged line by line. Changes that conflict are surrounded by
+// conflict markers like git, and conflicting files are recorded in Result.Conflicts.
+// Files are recorded in Result.Templates when created or merged, and in Result.Deleted
+// when deleted because the template removed them and the project did not change them.
+// Options such as WithDryRun and WithDiff are respected.
+func Upgrade(oldRoot, newRoot, project string, params map[string]string, options ...ApplyOption) (*Result, error) {
+	return UpgradeContext(context.Background(), oldRoot, newRoot, project, params, options...)
+}
+
+// UpgradeContext upgrades project like Upgrade, and can be canceled like ApplyContext.
+func UpgradeContext(ctx context.Context, oldRoot, newRoot, project string, params map[string]string, options ...ApplyOption) (*Result, error) {
+	proc := new(processor.Processor)
+	for _, opt := range options {
+		opt(proc)
+	}
+	proc.Initialize()
+
+	return proc.Upgrade(ctx, oldRoot, newRoot, project, params)
+}
+
+// Param describes a parameter used by templates.
+type Param = processor.Param
+
+// ParamUse is where a parameter is used in a template.
+type ParamUse = processor.ParamUse
+
+// ListParams parses all templates with the given root directory and returns the parameters
+// they use in the order they first appear, including the type of any default value, the prompt,
+// and where each parameter is used. No templates are executed and no users are prompted.
+// Options such as WithDelims and WithExclusions are respected.
+func ListParams(root string, options ...ApplyOption) ([]*Param, error) {
 	proc := new(processor.Processor)
 	for _, opt := range options {
 		opt(proc)
 	}
 	proc.Initialize()
 
-	return proc.Execute(root, params)
+	return proc.ListParams(root)
 }
 
 // WithOutput specifies the output Writer and whether it represents a TTY.
@@ -35,6 +153,15 @@ func WithOutput(w io.Writer, isTTY bool) ApplyOption {
 	}
 }
 
+// WithPrompter specifies a Prompter to prompt users for parameters instead of prompting on the
+// output specified by WithOutput and reading from the input specified by WithInput.
+// The Prompter is used even if the output is not a terminal, but not in non-interactive mode.
+func WithPrompter(prompter Prompter) ApplyOption {
+	return func(p *processor.Processor) {
+		p.Prompter = prompter
+	}
+}
+
 // WithInput specifies the input Reader. By default this is os.Stdin.
 func WithInput(r io.Reader) ApplyOption {
 	return func(p *processor.Processor) {
@@ -55,6 +182,25 @@ func WithDelims(left, right string) ApplyOption {
 	}
 }
 
+// WithDryRun renders all templates into memory without changing any files,
+// recording the files that would be created, rewritten, skipped, or deleted in plan.
+func WithDryRun(plan *Plan) ApplyOption {
+	return func(p *processor.Processor) {
+		p.DryRun = true
+		p.Plan = plan
+	}
+}
+
+// WithDiff renders all templates into memory without changing any files, like WithDryRun,
+// and writes a unified diff between each template and its rendered output to w.
+// Files that would be deleted, and emitted files, are compared to /dev/null.
+func WithDiff(w io.Writer) ApplyOption {
+	return func(p *processor.Processor) {
+		p.DryRun = true
+		p.Diff = w
+	}
+}
+
 // WithExclusions specifies excluded directories and files. These paths should be
 // relative to the root directory passed to Apply. The prefixes "./" and "/" are
 // automatically removed. Comparisons are case-insensitive.
@@ -64,6 +210,80 @@ func WithExclusions(exclusions []string) ApplyOption {
 	}
 }
 
+// WithKeepPartial specifies whether to write successfully rendered templates and
+// delete files even if other templates failed or processing was canceled.
+// By default, no files are written or deleted unless all templates succeed,
+// and any changes already made are rolled back if a file cannot be written or deleted.
+func WithKeepPartial(keep bool) ApplyOption {
+	return func(p *processor.Processor) {
+		p.KeepPartial = keep
+	}
+}
+
+// WithParamsFile reads parameters from a JSON, YAML, TOML, or .env file before templates are executed.
+// The format is determined by the file extension or, if unknown, detected from the content.
+// Nested objects or tables are flattened into dotted names like "github.owner".
+// If path is "-", parameters are read from the input specified by WithInput.
+// This option can be specified more than once; values in later files override those in earlier files,
+// and parameters passed to Apply take precedence over all files.
+func WithParamsFile(path string) ApplyOption {
+	return func(p *processor.Processor) {
+		p.ParamsFiles = append(p.ParamsFiles, path)
+	}
+}
+
+// WithEnvParams reads parameters not otherwise supplied from environment variables with the given prefix
+// before prompting. The variable name is the prefix, an underscore, and the parameter name in uppercase
+// with "." and "-" replaced by "_" e.g., TEMPLATE_GITHUB_OWNER for prefix "TEMPLATE" and "github.owner".
+func WithEnvParams(prefix string) ApplyOption {
+	return func(p *processor.Processor) {
+		p.EnvPrefix = prefix
+	}
+}
+
+// WithAnswersFile saves all resolved parameters to the named JSON file relative to the
+// destination root, and reads it on later runs so users are not prompted again for the same
+// parameters. Parameters that are passed, read from files, or read from environment variables
+// take precedence over saved answers. The conventional name is DefaultAnswersFile.
+func WithAnswersFile(name string) ApplyOption {
+	return func(p *processor.Processor) {
+		p.AnswersFile = name
+	}
+}
+
+// WithNonInteractive never prompts users for parameters, even if the output is a terminal.
+// If acceptDefaults is true, the default value is used for any parameter not otherwise supplied that
+// has a default other than an empty string; otherwise, or for parameters without a default, an error is returned.
+func WithNonInteractive(acceptDefaults bool) ApplyOption {
+	return func(p *processor.Processor) {
+		p.NonInteractive = true
+		p.AcceptDefaults = acceptDefaults
+	}
+}
+
+// WithValidator validates values of the named parameter, whether passed, read from files or environment
+// variables, saved as answers, or prompted. The value is passed formatted as a string, or for lists as a
+// JSON array of strings. Errors are shown when prompting, or returned for values not prompted.
+// Validators are applied in addition to any pattern or other rules declared in a manifest.
+func WithValidator(name string, validate func(string) error) ApplyOption {
+	return func(p *processor.Processor) {
+		if p.Validators == nil {
+			p.Validators = make(map[string]func(string) error)
+		}
+		p.Validators[name] = validate
+	}
+}
+
+// WithGitDefaults uses values from git config as defaults for the parameters "git.name" and "git.email"
+// from user.name and user.email, and "github.owner" and "github.repo" parsed from the URL of the origin remote
+// if hosted on GitHub. The user's git config files are read first, followed by .git/config in the destination root.
+// Defaults declared in templates or a manifest take precedence.
+func WithGitDefaults() ApplyOption {
+	return func(p *processor.Processor) {
+		p.GitDefaults = true
+	}
+}
+
 // WithLanguage specifies the language for any template function that needs it.
 // The default is language.English.
 func WithLanguage(language language.Tag) ApplyOption {
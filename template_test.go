@@ -4,13 +4,33 @@
 package template
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/heaths/go-template/internal/processor"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/text/language"
 )
 
+func TestApply_errors(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.md"), []byte(`{{param "name"`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b.md"), []byte(`{{param "name"}}`), 0644))
+
+	err := Apply(root, map[string]string{"name": "template"}, WithDryRun(nil))
+	require.Error(t, err)
+
+	var templateErr *TemplateError
+	require.True(t, errors.As(err, &templateErr))
+	assert.Equal(t, "a.md", filepath.Base(templateErr.Path))
+	assert.Equal(t, PhaseParse, templateErr.Phase)
+}
+
 func TestWithLanguage(t *testing.T) {
 	p := new(processor.Processor)
 	WithLanguage(language.English)(p)
@@ -19,6 +39,22 @@ func TestWithLanguage(t *testing.T) {
 	assert.Equal(t, language.English, *p.Language)
 }
 
+func TestWithValidator(t *testing.T) {
+	t.Parallel()
+
+	p := new(processor.Processor)
+	WithValidator("name", func(s string) error {
+		return nil
+	})(p)
+	WithValidator("year", func(s string) error {
+		return nil
+	})(p)
+
+	assert.Len(t, p.Validators, 2)
+	assert.Contains(t, p.Validators, "name")
+	assert.Contains(t, p.Validators, "year")
+}
+
 func TestWithDelims(t *testing.T) {
 	t.Parallel()
 